@@ -18,15 +18,13 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/mesos/mesos-go/api/v1/lib/agent"
 	"github.com/mesos/mesos-go/api/v1/lib/agent/calls"
 	"github.com/mesos/mesos-go/api/v1/lib/httpcli"
 	"github.com/mesos/mesos-go/api/v1/lib/httpcli/httpagent"
-	masterH "github.com/mesos/mesos-go/api/v1/lib/httpcli/httpmaster"
-	"github.com/mesos/mesos-go/api/v1/lib/master"
-	masterC "github.com/mesos/mesos-go/api/v1/lib/master/calls"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -50,55 +48,46 @@ var agentCmd = &cobra.Command{
 		}
 
 		var err error
-		if !strings.Contains(agentOpts.name, ":") {
-			agentCli, err = getCli(fmt.Sprintf("%s:%d", agentOpts.name, viper.GetUint32("agent.port")))
-			if err != nil {
-				agentCli, err = getCli(agentOpts.name)
-			}
-		} else {
-			agentCli, err = getCli(agentOpts.name)
+		agentCli, err = resolveAgentCli(agentOpts.name)
+		return err
+	},
+}
+
+// resolveAgentCli returns a client to the agent, as hostname:port, hostname with --agent-port,
+// or, with master.url configured, agent id or hostname prefix
+func resolveAgentCli(name string) (calls.Sender, error) {
+	var cli calls.Sender
+	var err error
+	if !strings.Contains(name, ":") {
+		cli, err = getCli(fmt.Sprintf("%s:%d", name, viper.GetUint32("agent.port")))
+		if err != nil {
+			cli, err = getCli(name)
 		}
+	} else {
+		cli, err = getCli(name)
+	}
 
-		if err != nil && viper.IsSet("master.url") && viper.GetString("master.url") != "" {
-			if verbose {
-				fmt.Printf("Trying to contact master at %s\n", viper.GetString("master.url"))
-			}
-			var auth httpcli.ConfigOpt
-			if viper.IsSet("principal") && viper.GetString("principal") != "" {
-				auth = httpcli.BasicAuth(
-					viper.GetString("principal"),
-					viper.GetString("secret"))
-			}
-			var masterCli = masterH.NewSender(
-				httpcli.New(
-					httpcli.Endpoint(fmt.Sprintf("%s/api/v1", viper.GetString("master.url"))),
-					httpcli.Do(httpcli.With(auth))).Send)
-			resp, merr := masterCli.Send(context.Background(), masterC.NonStreaming(masterC.GetAgents()))
-			if merr == nil {
-				var r master.Response
-				merr = resp.Decode(&r)
-				if merr == nil {
-					for _, a := range r.GetGetAgents().GetAgents() {
-						if strings.HasPrefix(a.GetAgentInfo().ID.Value, agentOpts.name) ||
-							strings.HasPrefix(a.GetAgentInfo().Hostname, agentOpts.name) {
-							agentCli, err = getCli(fmt.Sprintf("%s:%d", a.GetAgentInfo().Hostname, *a.GetAgentInfo().Port))
-							if err == nil {
-								return nil
-							}
-						}
-					}
-					merr = fmt.Errorf("Unable to find agent with id or hostname starting with %s", agentOpts.name)
+	if err != nil && viper.IsSet("master.url") && viper.GetString("master.url") != "" {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Trying to contact master at %s\n", viper.GetString("master.url"))
+		}
+		agents, merr := findAgents(newMasterCli(viper.GetString("master.url")), name)
+		if merr == nil {
+			for _, a := range agents {
+				cli, err = getCli(fmt.Sprintf("%s:%d", a.GetAgentInfo().Hostname, *a.GetAgentInfo().Port))
+				if err == nil {
+					return cli, nil
 				}
 			}
-			if merr != nil {
-				return fmt.Errorf("Unable to reach agent: %s and master: %s", err, merr)
-			}
 		}
-		if err != nil {
-			return fmt.Errorf("Unable to reach agent: %s", err)
+		if merr != nil {
+			return nil, fmt.Errorf("Unable to reach agent: %s and master: %s", err, merr)
 		}
-		return nil
-	},
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to reach agent: %s", err)
+	}
+	return cli, nil
 }
 
 var agentSubCommandUsageTemplate = `Usage:{{if .Runnable}}
@@ -125,6 +114,11 @@ Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
 Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
 `
 
+// agentNestedSubCommandUsageTemplate is the usage template of agent sub commands of sub commands
+var agentNestedSubCommandUsageTemplate = strings.Replace(agentSubCommandUsageTemplate,
+	"{{.Parent.Parent.CommandPath}} agent [agent] {{.Use}}",
+	"{{.Parent.Parent.Parent.CommandPath}} agent [agent] {{.Parent.Name}} {{.Use}}", 1)
+
 func init() {
 	rootCmd.AddCommand(agentCmd)
 
@@ -164,7 +158,7 @@ func init() {
 
 func getCli(url string) (calls.Sender, error) {
 	if verbose {
-		fmt.Printf("Trying agent %s\n", url)
+		fmt.Fprintf(os.Stderr, "Trying agent %s\n", url)
 	}
 	var auth httpcli.ConfigOpt
 	if viper.IsSet("principal") && viper.GetString("principal") != "" {
@@ -181,11 +175,43 @@ func getCli(url string) (calls.Sender, error) {
 	return cli, err
 }
 
+// sendAgentQuery sends a non streaming call to the agent and decodes its response
+func sendAgentQuery(cli calls.Sender, call *agent.Call) (*agent.Response, error) {
+	resp, err := cli.Send(context.Background(), calls.NonStreaming(call))
+	defer func() {
+		if resp != nil {
+			resp.Close()
+		}
+	}()
+	if err != nil {
+		return nil, fmt.Errorf("Error sending call: %s", err)
+	}
+	var r agent.Response
+	if err = resp.Decode(&r); err != nil {
+		return nil, fmt.Errorf("Error decoding response: %s", err)
+	}
+	return &r, nil
+}
+
+// sendAgentCommand sends a non streaming call whose response has no body
+func sendAgentCommand(cli calls.Sender, call *agent.Call) error {
+	resp, err := cli.Send(context.Background(), calls.NonStreaming(call))
+	defer func() {
+		if resp != nil {
+			resp.Close()
+		}
+	}()
+	if err != nil {
+		return fmt.Errorf("Error sending call: %s", err)
+	}
+	return nil
+}
+
 type AgentCallDef struct {
 	call  func() *agent.Call
 	desc  string
 	json  func(r *agent.Response) ([]byte, error)
-	print func(r *agent.Response) error
+	print func(r *agent.Response, format string) error
 }
 
 type AgentCallsDef map[string]AgentCallDef
@@ -0,0 +1,53 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/mesos/mesos-go/api/v1/lib/agent/calls"
+	"github.com/spf13/cobra"
+)
+
+type agentAttachOptions struct {
+	interactive       bool
+	tty               bool
+	parentContainerId string
+}
+
+var agentAttachOpts = agentAttachOptions{}
+
+var agentAttachCmd = &cobra.Command{
+	Use:     "attach [flags] [container-id]",
+	Example: "attach -ti a3cfce28-bcca-46d2-a23c-8c780246b7ae.e4b7a9f1-2c8d-4c0a-9d4e-2b8f3c6d1a7e",
+	Short:   "Attach to a running container",
+	Long: `Attach to the output of a running container (ATTACH_CONTAINER_OUTPUT) and,
+with --interactive, to its input (ATTACH_CONTAINER_INPUT).
+
+Nested containers are specified with --parent or as parent.child.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		containerId := newContainerID(args[0], agentAttachOpts.parentContainerId)
+		return runSession(agentCli, calls.AttachContainerOutput(containerId), containerId, agentAttachOpts.interactive, agentAttachOpts.tty)
+	},
+}
+
+func init() {
+	agentCmd.AddCommand(agentAttachCmd)
+	agentAttachCmd.Flags().BoolVarP(&agentAttachOpts.interactive, "interactive", "i", false, "interactive session (handle STDIN, type ~? after a newline for escape sequences)")
+	agentAttachCmd.Flags().BoolVarP(&agentAttachOpts.tty, "tty", "t", false, "container has a TTY (forward terminal size)")
+	agentAttachCmd.Flags().StringVarP(&agentAttachOpts.parentContainerId, "parent", "p", "", "parent container ID")
+
+	agentAttachCmd.SetUsageTemplate(agentSubCommandUsageTemplate)
+}
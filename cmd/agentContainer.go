@@ -0,0 +1,199 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/agent"
+	"github.com/mesos/mesos-go/api/v1/lib/agent/calls"
+	"github.com/spf13/cobra"
+)
+
+type agentContainerOptions struct {
+	parentContainerId string
+	signal            string
+}
+
+var agentContainerOpts = agentContainerOptions{}
+
+var signals = map[string]int32{
+	"HUP":  1,
+	"INT":  2,
+	"QUIT": 3,
+	"KILL": 9,
+	"USR1": 10,
+	"USR2": 12,
+	"TERM": 15,
+}
+
+// newContainerID builds a container ID, nested in parent if set.
+// Nested container IDs can also be written as parent.child (example: a3cfce28.e4b7a9f1).
+func newContainerID(id string, parent string) mesos.ContainerID {
+	if parent == "" {
+		if i := strings.LastIndex(id, "."); i > 0 {
+			parent, id = id[:i], id[i+1:]
+		}
+	}
+	containerId := mesos.ContainerID{Value: id}
+	if parent != "" {
+		p := newContainerID(parent, "")
+		containerId.Parent = &p
+	}
+	return containerId
+}
+
+// formatContainerID returns the container ID as parent.child for nested containers
+func formatContainerID(containerId mesos.ContainerID) string {
+	if containerId.Parent != nil {
+		return fmt.Sprintf("%s.%s", formatContainerID(*containerId.Parent), containerId.Value)
+	}
+	return containerId.Value
+}
+
+// exitCode converts a wait(2) status to a shell like exit code
+func exitCode(status int32) int {
+	if status&0x7f == 0 {
+		return int(status>>8) & 0xff
+	}
+	return 128 + int(status&0x7f)
+}
+
+func parseSignal(s string) (int32, error) {
+	if sig, ok := signals[strings.TrimPrefix(strings.ToUpper(s), "SIG")]; ok {
+		return sig, nil
+	}
+	sig, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Unknown signal %s", s)
+	}
+	return int32(sig), nil
+}
+
+// waitContainer waits for the container to terminate and returns its exit status, if any
+func waitContainer(cli calls.Sender, containerId mesos.ContainerID) (*int32, error) {
+	var call *agent.Call
+	if containerId.Parent != nil {
+		call = calls.WaitNestedContainer(containerId)
+	} else {
+		call = calls.WaitContainer(containerId)
+	}
+	r, err := sendAgentQuery(cli, call)
+	if err != nil {
+		return nil, err
+	}
+	if containerId.Parent != nil {
+		return r.GetWaitNestedContainer().ExitStatus, nil
+	}
+	return r.GetWaitContainer().ExitStatus, nil
+}
+
+func sendContainerCall(nested func(mesos.ContainerID) *agent.Call, standalone func(mesos.ContainerID) *agent.Call, containerId mesos.ContainerID) error {
+	call := standalone(containerId)
+	if containerId.Parent != nil {
+		call = nested(containerId)
+	}
+	return sendAgentCommand(agentCli, call)
+}
+
+var agentContainerCmd = &cobra.Command{
+	Use:   "container",
+	Short: "Wait, kill or remove containers",
+	Long: `Wait, kill or remove containers.
+
+Nested containers are specified with --parent or as parent.child.`,
+}
+
+var agentContainerWaitCmd = &cobra.Command{
+	Use:     "wait [container-id]",
+	Short:   "Wait for a container to terminate",
+	Example: "container wait -p a3cfce28-bcca-46d2-a23c-8c780246b7ae e4b7a9f1-2c8d-4c0a-9d4e-2b8f3c6d1a7e",
+	Long:    "Wait for a container to terminate and exit with its exit status.",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		containerId := newContainerID(args[0], agentContainerOpts.parentContainerId)
+		status, err := waitContainer(agentCli, containerId)
+		if err != nil {
+			return err
+		}
+		if status == nil {
+			return fmt.Errorf("Container %s terminated without exit status", formatContainerID(containerId))
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Container %s exited with status %d\n", formatContainerID(containerId), exitCode(*status))
+		}
+		os.Exit(exitCode(*status))
+		return nil
+	},
+}
+
+var agentContainerKillCmd = &cobra.Command{
+	Use:     "kill [container-id]",
+	Short:   "Kill a container",
+	Example: "container kill --signal TERM a3cfce28-bcca-46d2-a23c-8c780246b7ae.e4b7a9f1-2c8d-4c0a-9d4e-2b8f3c6d1a7e",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		containerId := newContainerID(args[0], agentContainerOpts.parentContainerId)
+		var signal *int32
+		if agentContainerOpts.signal != "" {
+			sig, err := parseSignal(agentContainerOpts.signal)
+			if err != nil {
+				return err
+			}
+			signal = &sig
+		}
+		return sendContainerCall(
+			func(c mesos.ContainerID) *agent.Call {
+				call := calls.KillNestedContainer(c)
+				call.KillNestedContainer.Signal = signal
+				return call
+			},
+			func(c mesos.ContainerID) *agent.Call {
+				call := calls.KillContainer(c)
+				call.KillContainer.Signal = signal
+				return call
+			},
+			containerId)
+	},
+}
+
+var agentContainerRemoveCmd = &cobra.Command{
+	Use:     "remove [container-id]",
+	Short:   "Remove a terminated container",
+	Example: "container remove a3cfce28-bcca-46d2-a23c-8c780246b7ae.e4b7a9f1-2c8d-4c0a-9d4e-2b8f3c6d1a7e",
+	Long:    "Remove a terminated container and its runtime and sandbox directories.",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		containerId := newContainerID(args[0], agentContainerOpts.parentContainerId)
+		return sendContainerCall(calls.RemoveNestedContainer, calls.RemoveContainer, containerId)
+	},
+}
+
+func init() {
+	agentCmd.AddCommand(agentContainerCmd)
+	agentContainerCmd.AddCommand(agentContainerWaitCmd, agentContainerKillCmd, agentContainerRemoveCmd)
+	agentContainerCmd.PersistentFlags().StringVarP(&agentContainerOpts.parentContainerId, "parent", "p", "", "parent container ID")
+	agentContainerKillCmd.Flags().StringVarP(&agentContainerOpts.signal, "signal", "s", "", "signal to send, name or number (default KILL)")
+
+	agentContainerCmd.SetUsageTemplate(agentSubCommandUsageTemplate)
+	for _, c := range agentContainerCmd.Commands() {
+		c.SetUsageTemplate(agentNestedSubCommandUsageTemplate)
+	}
+}
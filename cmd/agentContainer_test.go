@@ -0,0 +1,61 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"testing"
+)
+
+func TestNewContainerID(t *testing.T) {
+	tests := []struct {
+		id     string
+		parent string
+		want   string
+		nested bool
+	}{
+		{"a", "", "a", false},
+		{"b", "a", "a.b", true},
+		{"a.b", "", "a.b", true},
+		{"a.b.c", "", "a.b.c", true},
+		{"c", "a.b", "a.b.c", true},
+		{".a", "", ".a", false},
+	}
+	for _, test := range tests {
+		got := newContainerID(test.id, test.parent)
+		if formatContainerID(got) != test.want || (got.Parent != nil) != test.nested {
+			t.Errorf("newContainerID(%q, %q) = %s (nested: %v), want %s (nested: %v)",
+				test.id, test.parent, formatContainerID(got), got.Parent != nil, test.want, test.nested)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		status int32
+		want   int
+	}{
+		{0, 0},
+		{1 << 8, 1},
+		{255 << 8, 255},
+		{9, 137},
+		{15, 143},
+	}
+	for _, test := range tests {
+		if got := exitCode(test.status); got != test.want {
+			t.Errorf("exitCode(%d) = %d, want %d", test.status, got, test.want)
+		}
+	}
+}
@@ -19,7 +19,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -32,6 +31,7 @@ import (
 type agentGetOptions struct {
 	timeout time.Duration
 	json    bool
+	output  string
 }
 
 var agentGetOpts = agentGetOptions{}
@@ -43,8 +43,8 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetHealth(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			fmt.Println(r.GetGetHealth().GetHealthy())
+		print: func(r *agent.Response, format string) error {
+			printRecord(format, []string{"healthy"}, []string{fmt.Sprintf("%v", r.GetGetHealth().GetHealthy())})
 			return nil
 		},
 	},
@@ -54,8 +54,8 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetLoggingLevel(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			fmt.Println(r.GetGetLoggingLevel().GetLevel())
+		print: func(r *agent.Response, format string) error {
+			printRecord(format, []string{"level"}, []string{fmt.Sprintf("%d", r.GetGetLoggingLevel().GetLevel())})
 			return nil
 		},
 	},
@@ -65,20 +65,18 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetAgent(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *agent.Response, format string) error {
 			ai := r.GetGetAgent().GetAgentInfo()
 			d := r.GetGetAgent().GetDrainConfig()
-			table.Append([]string{"id:", ai.GetID().GetValue()})
-			table.Append([]string{"hostname:", ai.GetHostname()})
-			table.Append([]string{"port:", fmt.Sprintf("%d", ai.GetPort())})
-			table.Append([]string{"max_grace_period:", time.Duration(d.GetMaxGracePeriod().GetNanoseconds()).String()})
-			table.Append([]string{"mark_gone:", fmt.Sprintf("%v", d.GetMarkGone())})
-			table.SetBorder(false)
-			table.SetHeaderLine(false)
-			table.SetColumnSeparator("")
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.Render()
+			printRecord(format,
+				[]string{"id", "hostname", "port", "max_grace_period", "mark_gone"},
+				[]string{
+					ai.GetID().GetValue(),
+					ai.GetHostname(),
+					fmt.Sprintf("%d", ai.GetPort()),
+					time.Duration(d.GetMaxGracePeriod().GetNanoseconds()).String(),
+					fmt.Sprintf("%v", d.GetMarkGone()),
+				})
 			return nil
 		},
 	},
@@ -96,8 +94,9 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetMetrics(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *agent.Response, format string) error {
+			table := newTable(format)
+			table.SetHeader([]string{"name", "value"})
 			for _, m := range r.GetGetMetrics().GetMetrics() {
 				table.Append([]string{m.GetName(), fmt.Sprintf("%f", m.GetValue())})
 			}
@@ -114,8 +113,8 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetOperations(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *agent.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"framework", "type", "status"})
 			for _, o := range r.GetGetOperations().GetOperations() {
 				table.Append([]string{o.GetFrameworkID().GetValue(), o.GetInfo().Type.String(), o.GetLatestStatus().State.String()})
@@ -142,8 +141,8 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetContainers(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *agent.Response, format string) error {
+			table := newTable(format)
 			//TODO show nesting tree
 			table.SetHeader([]string{"framework", "id", "executor_id", "executor_name"})
 			for _, c := range r.GetGetContainers().GetContainers() {
@@ -179,8 +178,8 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetTasks(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *agent.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"framework", "task_id", "type", "state"})
 			for _, task := range r.GetGetTasks().GetPendingTasks() {
 				table.Append([]string{task.GetFrameworkID().Value, task.GetTaskID().Value, "pending", task.GetState().String()})
@@ -211,20 +210,18 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetVersion(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
-			table.Append([]string{"version:", r.GetGetVersion().VersionInfo.GetVersion()})
-			table.Append([]string{"build_date:", r.GetGetVersion().VersionInfo.GetBuildDate()})
-			table.Append([]string{"build_time:", fmt.Sprintf("%v", int64(r.GetGetVersion().VersionInfo.GetBuildTime()))})
-			table.Append([]string{"build_user:", r.GetGetVersion().VersionInfo.GetBuildUser()})
-			table.Append([]string{"git_branch:", r.GetGetVersion().VersionInfo.GetGitBranch()})
-			table.Append([]string{"git_sha:", r.GetGetVersion().VersionInfo.GetGitSHA()})
-			table.Append([]string{"git_tag:", r.GetGetVersion().VersionInfo.GetGitTag()})
-			table.SetBorder(false)
-			table.SetHeaderLine(false)
-			table.SetColumnSeparator("")
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.Render()
+		print: func(r *agent.Response, format string) error {
+			printRecord(format,
+				[]string{"version", "build_date", "build_time", "build_user", "git_branch", "git_sha", "git_tag"},
+				[]string{
+					r.GetGetVersion().VersionInfo.GetVersion(),
+					r.GetGetVersion().VersionInfo.GetBuildDate(),
+					fmt.Sprintf("%v", int64(r.GetGetVersion().VersionInfo.GetBuildTime())),
+					r.GetGetVersion().VersionInfo.GetBuildUser(),
+					r.GetGetVersion().VersionInfo.GetGitBranch(),
+					r.GetGetVersion().VersionInfo.GetGitSHA(),
+					r.GetGetVersion().VersionInfo.GetGitTag(),
+				})
 			return nil
 		},
 	},
@@ -234,8 +231,8 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetExecutors(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *agent.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"framework", "id", "name"})
 			for _, e := range r.GetGetExecutors().GetExecutors() {
 				ei := e.GetExecutorInfo()
@@ -259,8 +256,8 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetFlags(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *agent.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"name", "value"})
 			for _, f := range r.GetGetFlags().GetFlags() {
 				table.Append([]string{f.GetName(), f.GetValue()})
@@ -279,8 +276,8 @@ var agentGetCalls = AgentCallsDef{
 		json: func(r *agent.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetFrameworks(), "", "  ")
 		},
-		print: func(r *agent.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *agent.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"id", "name", "roles", "principal"})
 			for _, f := range r.GetGetFrameworks().GetFrameworks() {
 				fi := f.GetFrameworkInfo()
@@ -325,6 +322,13 @@ var agentGetCmd = &cobra.Command{
 	Args:  agentGetCalls.validateArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key := strings.Join(args, " ")
+		format, tmpl, err := parseOutputFlags(agentGetOpts.output, agentGetOpts.json)
+		if err != nil {
+			return err
+		}
+		if key == "state" && isCSVOutput(format) {
+			return fmt.Errorf("Output format %s is not supported by get state, which prints several tables", format)
+		}
 		resp, err := agentCli.Send(context.Background(), calls.NonStreaming(agentGetCalls[key].call()))
 		defer func() {
 			if resp != nil {
@@ -339,22 +343,23 @@ var agentGetCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("Error decoding response: %s", err)
 		}
-		if agentGetOpts.json || agentGetCalls[key].print == nil {
-			decode := agentGetCalls[key].json
-			if decode == nil {
-				decode = func(r *agent.Response) ([]byte, error) {
-					return json.MarshalIndent(r, "", "  ")
-				}
-			}
-			if j, err := decode(&e); err == nil {
-				fmt.Println(string(j))
-			} else {
-				return fmt.Errorf("Error marshalling response as JSON: %s", err)
+		if isTableOutput(format) && agentGetCalls[key].print != nil {
+			return agentGetCalls[key].print(&e, format)
+		}
+		decode := agentGetCalls[key].json
+		if decode == nil {
+			decode = func(r *agent.Response) ([]byte, error) {
+				return json.MarshalIndent(r, "", "  ")
 			}
-		} else {
-			agentGetCalls[key].print(&e)
 		}
-		return nil
+		j, err := decode(&e)
+		if err != nil {
+			return fmt.Errorf("Error marshalling response as JSON: %s", err)
+		}
+		if isTableOutput(format) {
+			format = "json"
+		}
+		return printStructured(j, format, tmpl)
 	},
 }
 
@@ -362,12 +367,13 @@ func init() {
 	agentCmd.AddCommand(agentGetCmd)
 	agentGetCmd.Flags().DurationVar(&agentGetOpts.timeout, "timeout", 0, "timeout duration (used for metrics call see --help)")
 	agentGetCmd.Flags().BoolVarP(&agentGetOpts.json, "json", "j", false, "json output")
+	addOutputFlag(agentGetCmd, &agentGetOpts.output)
 
 	agentGetCmd.SetUsageTemplate(agentSubCommandUsageTemplate)
 
 	// GetState calls other actions
 	stateCall := agentGetCalls["state"]
-	stateCall.print = func(r *agent.Response) error {
+	stateCall.print = func(r *agent.Response, format string) error {
 		fr := agent.Response{
 			GetFrameworks: r.GetGetState().GetGetFrameworks(),
 			GetExecutors:  r.GetGetState().GetGetExecutors(),
@@ -375,7 +381,7 @@ func init() {
 		}
 		for _, call := range []string{"frameworks", "executors", "tasks"} {
 			fmt.Printf("\nState of %s:\n", call)
-			if err := agentGetCalls[call].print(&fr); err != nil {
+			if err := agentGetCalls[call].print(&fr, format); err != nil {
 				return err
 			}
 		}
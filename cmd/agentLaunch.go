@@ -20,6 +20,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/google/uuid"
 	mesos "github.com/mesos/mesos-go/api/v1/lib"
@@ -35,169 +38,341 @@ type agentLaunchOptions struct {
 	detach           bool
 	containerId      string
 	parentContinerId string
+	resources        string
+	dockerImage      string
+	appcImage        string
 }
 
 var agentLaunchOpts = agentLaunchOptions{}
 
+// heartbeatInterval is the interval of heartbeats sent on interactive sessions input,
+// to keep idle connections open through load balancers and proxies
+const heartbeatInterval = 30 * time.Second
+
+const escapeHelp = "Supported escape sequences:\r\n" +
+	"  ~.  - detach\r\n" +
+	"  ~?  - this message\r\n" +
+	"  ~~  - send the escape character by typing it twice\r\n" +
+	"(Note that escapes are only recognized immediately after newline.)\r\n"
+
 var agentLaunchCmd = &cobra.Command{
 	Use:     "launch [flags] [command]",
 	Example: "launch -p a3cfce28-bcca-46d2-a23c-8c780246b7ae -ti bash",
 	Short:   "Launch container on agent",
-	Args:    cobra.MinimumNArgs(1),
+	Long: `Launch a container on agent.
+
+With --parent, a nested container is launched in the parent container and the command
+output is streamed until it exits, unless --detach is set.
+Without --parent, a standalone container is launched with --resources, always detached.
+
+The ID of detached containers is printed.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var call *agent.Call
-		var containerId = mesos.ContainerID{
-			Value: agentLaunchOpts.containerId,
-		}
-		if agentLaunchOpts.parentContinerId != "" {
-			containerId.Parent = &mesos.ContainerID{Value: agentLaunchOpts.parentContinerId}
-		}
-		shell := false
-		var commandInfo = &mesos.CommandInfo{
-			Shell:     &shell,
-			Value:     &args[0],
-			Arguments: args,
+		var containerId = newContainerID(agentLaunchOpts.containerId, agentLaunchOpts.parentContinerId)
+		commandInfo, containerInfo := newLaunchInfos(args, agentLaunchOpts.tty)
+		if agentLaunchOpts.dockerImage != "" && agentLaunchOpts.appcImage != "" {
+			return fmt.Errorf("Only one of --docker-image and --appc-image can be set")
 		}
-		var containerInfo = &mesos.ContainerInfo{
-			Type:  mesos.ContainerInfo_MESOS.Enum(),
-			Mesos: &mesos.ContainerInfo_MesosInfo{},
+		if agentLaunchOpts.dockerImage != "" {
+			containerInfo.Mesos.Image = &mesos.Image{
+				Type:   mesos.Image_DOCKER.Enum(),
+				Docker: &mesos.Image_Docker{Name: agentLaunchOpts.dockerImage},
+			}
 		}
-		if agentLaunchOpts.tty {
-			containerInfo.TTYInfo = &mesos.TTYInfo{}
+		if agentLaunchOpts.appcImage != "" {
+			containerInfo.Mesos.Image = &mesos.Image{
+				Type: mesos.Image_APPC.Enum(),
+				Appc: &mesos.Image_Appc{Name: agentLaunchOpts.appcImage},
+			}
 		}
 		if agentLaunchOpts.parentContinerId != "" && !agentLaunchOpts.detach {
 			call = calls.LaunchNestedContainerSession(containerId, commandInfo, containerInfo)
 		} else {
-			return fmt.Errorf("Only nested containers are supported (no detach, and parent flag must be set)")
-			//call = calls.LaunchContainer(containerId, commandInfo, containerInfo, []mesos.Resource{})
+			if agentLaunchOpts.interactive {
+				return fmt.Errorf("Detached containers cannot be interactive")
+			}
+			if agentLaunchOpts.parentContinerId != "" {
+				call = calls.LaunchNestedContainer(containerId, commandInfo, containerInfo)
+			} else {
+				res, err := parseResources(agentLaunchOpts.resources)
+				if err != nil {
+					return fmt.Errorf("Standalone containers require --resources: %s", err)
+				}
+				call = calls.LaunchContainer(containerId, commandInfo, containerInfo, res)
+			}
+			resp, err := agentCli.Send(context.Background(), calls.NonStreaming(call))
+			defer func() {
+				if resp != nil {
+					resp.Close()
+				}
+			}()
+			if err != nil {
+				return fmt.Errorf("Error sending call: %s", err)
+			}
+			fmt.Println(formatContainerID(containerId))
+			return nil
 		}
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+		return runSession(agentCli, call, containerId, agentLaunchOpts.interactive, agentLaunchOpts.tty)
+	},
+}
 
-		resp, err := agentCli.Send(context.Background(), calls.NonStreaming(call))
+// newLaunchInfos builds the command and container infos to launch the command (not in a shell)
+func newLaunchInfos(args []string, tty bool) (*mesos.CommandInfo, *mesos.ContainerInfo) {
+	shell := false
+	var commandInfo = &mesos.CommandInfo{
+		Shell:     &shell,
+		Value:     &args[0],
+		Arguments: args,
+	}
+	var containerInfo = &mesos.ContainerInfo{
+		Type:  mesos.ContainerInfo_MESOS.Enum(),
+		Mesos: &mesos.ContainerInfo_MesosInfo{},
+	}
+	if tty {
+		containerInfo.TTYInfo = &mesos.TTYInfo{}
+	}
+	return commandInfo, containerInfo
+}
 
-		if agentLaunchOpts.interactive {
-			previousTerminalState, err := terminal.MakeRaw(int(os.Stdin.Fd()))
-			//TODO trap SIGWINCH to set TTY via calls.AttachContainerInputTTY()
-			if err == nil {
-				defer func() {
-					terminal.Restore(int(os.Stdin.Fd()), previousTerminalState)
-				}()
-			} else {
-				return fmt.Errorf("Failed to get raw TTY: %s", err.Error())
-			}
-			interactive(ctx, containerId)
+// runSession sends a streaming call (session launch or output attach) and writes the container
+// output to stdout and stderr until it ends. If interactive, stdin is forwarded to the container,
+// along with the terminal size if the container has a TTY.
+func runSession(cli calls.Sender, call *agent.Call, containerId mesos.ContainerID, withInput bool, tty bool) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resp, err := cli.Send(ctx, calls.NonStreaming(call))
+	defer func() {
+		if resp != nil {
+			resp.Close()
 		}
+	}()
+	if err != nil {
+		return fmt.Errorf("Error sending call: %s", err)
+	}
 
-		defer func() {
-			if resp != nil {
-				resp.Close()
-			}
-		}()
+	if withInput {
+		previousTerminalState, err := terminal.MakeRaw(int(os.Stdin.Fd()))
+		if err == nil {
+			defer func() {
+				terminal.Restore(int(os.Stdin.Fd()), previousTerminalState)
+			}()
+		} else {
+			return fmt.Errorf("Failed to get raw TTY: %s", err.Error())
+		}
+		interactive(ctx, cancel, cli, containerId, tty)
+	}
 
-		go func() {
-			for err == nil {
-				var e agent.ProcessIO
-				resp.Decode(&e)
-				if err != nil {
-					if err == io.EOF {
-						cancel()
-						return
-					}
-					fmt.Printf("Error decoding response: %s", err)
-					cancel()
-					return
-				}
-				switch e.GetType() {
-				case agent.ProcessIO_DATA:
-					var fd *os.File
-					switch e.GetData().GetType() {
-					case agent.ProcessIO_Data_STDIN:
-						fmt.Printf("Received STDIN data, this is not normal: %b", e.GetData().GetData())
-						cancel()
-						return
-					case agent.ProcessIO_Data_STDERR:
-						fd = os.Stderr
-					case agent.ProcessIO_Data_STDOUT:
-						fd = os.Stdout
-					default:
-						fmt.Printf("Received unknown data type: %s with data: %b", e.GetData().GetType(), e.GetData().GetData())
-						cancel()
-						return
+	err = processOutput(resp, os.Stdout, os.Stderr)
+	if ctx.Err() != nil {
+		// session detached with the escape sequence
+		return nil
+	}
+	return err
+}
 
-					}
-					fd.Write(e.GetData().GetData())
-				case agent.ProcessIO_CONTROL:
-					if e.GetControl().GetType() != agent.ProcessIO_Control_HEARTBEAT {
-						fmt.Printf("Received unknown Control: %s", e.GetControl().GetType())
-						cancel()
-						return
-					}
-				default:
-					fmt.Printf("Received unknown ProcessIO type: %s", e.GetType())
-					cancel()
-					return
-				}
+// processOutput decodes the ProcessIO messages of the response, writing container output to
+// stdout and stderr writers until the end of the stream
+func processOutput(resp mesos.Response, stdout io.Writer, stderr io.Writer) error {
+	for {
+		var e agent.ProcessIO
+		if err := resp.Decode(&e); err != nil {
+			if err == io.EOF {
+				return nil
 			}
-		}()
-		<-ctx.Done()
-
-		return err
-	},
+			return fmt.Errorf("Error decoding response: %s", err)
+		}
+		switch e.GetType() {
+		case agent.ProcessIO_DATA:
+			var fd io.Writer
+			switch e.GetData().GetType() {
+			case agent.ProcessIO_Data_STDIN:
+				return fmt.Errorf("Received STDIN data, this is not normal: %b", e.GetData().GetData())
+			case agent.ProcessIO_Data_STDERR:
+				fd = stderr
+			case agent.ProcessIO_Data_STDOUT:
+				fd = stdout
+			default:
+				return fmt.Errorf("Received unknown data type: %s with data: %b", e.GetData().GetType(), e.GetData().GetData())
+			}
+			fd.Write(e.GetData().GetData())
+		case agent.ProcessIO_CONTROL:
+			if e.GetControl().GetType() != agent.ProcessIO_Control_HEARTBEAT {
+				return fmt.Errorf("Received unknown Control: %s", e.GetControl().GetType())
+			}
+		default:
+			return fmt.Errorf("Received unknown ProcessIO type: %s", e.GetType())
+		}
+	}
 }
 
 func init() {
 	agentCmd.AddCommand(agentLaunchCmd)
 	agentLaunchCmd.Flags().BoolVarP(&agentLaunchOpts.tty, "tty", "t", false, "enable TTY")
-	//agentLaunchCmd.Flags().BoolVarP(&agentLaunchOpts.detach, "detach", "d", false, "detach from running container after launch")
-	agentLaunchCmd.Flags().BoolVarP(&agentLaunchOpts.interactive, "interactive", "i", false, "interactive run (handle STDIN)")
+	agentLaunchCmd.Flags().BoolVarP(&agentLaunchOpts.detach, "detach", "d", false, "detach from running container after launch")
+	agentLaunchCmd.Flags().BoolVarP(&agentLaunchOpts.interactive, "interactive", "i", false, "interactive run (handle STDIN, type ~? after a newline for escape sequences)")
 	agentLaunchCmd.Flags().StringVar(&agentLaunchOpts.containerId, "container-id", uuid.New().String(), "container ID")
 	agentLaunchCmd.Flags().StringVarP(&agentLaunchOpts.parentContinerId, "parent", "p", "", "parent container ID")
+	agentLaunchCmd.Flags().StringVar(&agentLaunchOpts.resources, "resources", "", "standalone container resources in the format 'name:value[,name:value...]' (example: 'cpus:0.1,mem:128')")
+	agentLaunchCmd.Flags().StringVar(&agentLaunchOpts.dockerImage, "docker-image", "", "docker image of the container")
+	agentLaunchCmd.Flags().StringVar(&agentLaunchOpts.appcImage, "appc-image", "", "appc image of the container")
 
 	agentLaunchCmd.SetUsageTemplate(agentSubCommandUsageTemplate)
 }
 
-func interactive(ctx context.Context, containerId mesos.ContainerID) {
+// terminalSize returns the TTY info with the window size of the terminal
+func terminalSize() (*mesos.TTYInfo, error) {
+	columns, rows, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	return &mesos.TTYInfo{
+		WindowSize: &mesos.TTYInfo_WindowSize{
+			Rows:    uint32(rows),
+			Columns: uint32(columns),
+		},
+	}, nil
+}
+
+// forwardTerminalSize sends the terminal size, then sends it again each time the terminal
+// is resized (SIGWINCH), until the context is done
+func forwardTerminalSize(ctx context.Context, input chan<- *agent.Call) {
+	if ttyInfo, err := terminalSize(); err == nil {
+		select {
+		case input <- calls.AttachContainerInputTTY(ttyInfo):
+		case <-ctx.Done():
+			return
+		}
+	}
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	go func() {
+		defer signal.Stop(resized)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-resized:
+				if ttyInfo, err := terminalSize(); err == nil {
+					select {
+					case input <- calls.AttachContainerInputTTY(ttyInfo):
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// heartbeat builds a ProcessIO heartbeat control message for the container input stream
+func heartbeat(interval time.Duration) *agent.Call {
+	return &agent.Call{
+		Type: agent.Call_ATTACH_CONTAINER_INPUT,
+		AttachContainerInput: &agent.Call_AttachContainerInput{
+			Type: agent.Call_AttachContainerInput_PROCESS_IO,
+			ProcessIO: &agent.ProcessIO{
+				Type: agent.ProcessIO_CONTROL,
+				Control: &agent.ProcessIO_Control{
+					Type: agent.ProcessIO_Control_HEARTBEAT,
+					Heartbeat: &agent.ProcessIO_Control_Heartbeat{
+						Interval: &mesos.DurationInfo{Nanoseconds: interval.Nanoseconds()},
+					},
+				},
+			},
+		},
+	}
+}
+
+// interactive forwards stdin to the container input until the context is done.
+// Like SSH, escape sequences are recognized after a newline: ~. detaches (cancelling
+// the session), ~? prints help and ~~ sends a literal ~.
+func interactive(ctx context.Context, cancel context.CancelFunc, cli calls.Sender, containerId mesos.ContainerID, tty bool) {
 	var input = make(chan *agent.Call)
+	send := func(call *agent.Call) bool {
+		select {
+		case input <- call:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
 	go func() {
-		resp, err := agentCli.Send(context.Background(), calls.FromChan(input))
+		resp, err := cli.Send(ctx, calls.FromChan(input))
 		defer func() {
 			if resp != nil {
 				resp.Close()
 			}
 		}()
-		if err != nil {
-			fmt.Printf("Error sending STDIN: %s\n", err.Error())
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Error sending STDIN: %s\r\n", err.Error())
 		}
-		//TODO send heartbeats
 	}()
 	go func() {
-		input <- calls.AttachContainerInput(containerId)
+		if !send(calls.AttachContainerInput(containerId)) {
+			return
+		}
+		if tty {
+			forwardTerminalSize(ctx, input)
+		}
+		go func() {
+			ticker := time.NewTicker(heartbeatInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if !send(heartbeat(heartbeatInterval)) {
+						return
+					}
+				}
+			}
+		}()
+
 		inBytes := make([]byte, 1024)
-		// escape sequence does not work at the moment
-		// TODO
-		escapeSequence := "\012~." //<Enter>~.
-		escapeIndex := 0
-		for true {
-			var size int
-			var err error
-			if size, err = os.Stdin.Read(inBytes); err != nil {
-				fmt.Printf("Error sending STDIN: %s\n", err.Error())
+		lineStart := true
+		escaped := false
+		for {
+			size, err := os.Stdin.Read(inBytes)
+			if err != nil {
+				if err != io.EOF {
+					fmt.Fprintf(os.Stderr, "Error reading STDIN: %s\r\n", err.Error())
+				}
 				return
 			}
+			data := []byte{}
 			for _, b := range inBytes[0:size] {
-				if b == escapeSequence[escapeIndex] {
-					escapeIndex++
-					if escapeIndex == 2 {
-						input <- calls.AttachContainerInputData([]byte("\004")) // EOT
+				switch {
+				case escaped:
+					escaped = false
+					switch b {
+					case '.':
+						fmt.Fprint(os.Stderr, "~.\r\n")
+						cancel()
 						return
+					case '?':
+						fmt.Fprint(os.Stderr, escapeHelp)
+						lineStart = true
+						continue
+					case '~':
+						data = append(data, '~')
+					default:
+						data = append(data, '~', b)
 					}
-				} else {
-					escapeIndex = 0
+					lineStart = false
+				case lineStart && b == '~':
+					escaped = true
+				default:
+					data = append(data, b)
+					lineStart = b == '\r' || b == '\n'
 				}
 			}
-			input <- calls.AttachContainerInputData(inBytes[0:size])
+			if len(data) > 0 && !send(calls.AttachContainerInputData(data)) {
+				return
+			}
 		}
 	}()
 }
@@ -16,10 +16,7 @@ limitations under the License.
 package cmd
 
 import (
-	"context"
-	"fmt"
-	"strings"
-
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
 	"github.com/mesos/mesos-go/api/v1/lib/agent"
 	"github.com/mesos/mesos-go/api/v1/lib/agent/calls"
 	"github.com/spf13/cobra"
@@ -27,6 +24,7 @@ import (
 
 type agentListOptions struct {
 	path string
+	listFilesOptions
 }
 
 var agentListOpts = &agentListOptions{}
@@ -47,28 +45,21 @@ var agentListCmd = &cobra.Command{
 	Long:  agentListCalls.describeCalls(),
 	Args:  agentListCalls.validateArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		key := strings.Join(args, " ")
-		resp, err := agentCli.Send(context.Background(), calls.NonStreaming(agentListCalls[key].call()))
-		defer func() {
-			if resp != nil {
-				resp.Close()
+		list := func(path string) ([]mesos.FileInfo, error) {
+			r, err := sendAgentQuery(agentCli, calls.ListFiles(path))
+			if err != nil {
+				return nil, err
 			}
-		}()
-		if err != nil {
-			return fmt.Errorf("Error sending call: %s", err)
-		}
-		var e agent.Response
-		err = resp.Decode(&e)
-		if err != nil {
-			return fmt.Errorf("Error decoding response: %s", err)
+			return r.GetListFiles().GetFileInfos(), nil
 		}
-		return nil
+		return listFiles(list, agentListOpts.path, agentListOpts.listFilesOptions)
 	},
 }
 
 func init() {
 	agentCmd.AddCommand(agentListCmd)
 	agentListCmd.Flags().StringVar(&agentListOpts.path, "path", "", "path to list files")
+	addListFilesFlags(agentListCmd, &agentListOpts.listFilesOptions)
 
 	agentListCmd.SetUsageTemplate(agentSubCommandUsageTemplate)
 }
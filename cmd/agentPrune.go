@@ -0,0 +1,84 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"strings"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/agent"
+	"github.com/mesos/mesos-go/api/v1/lib/agent/calls"
+	"github.com/spf13/cobra"
+)
+
+type agentPruneOptions struct {
+	exclude []string
+}
+
+var agentPruneOpts = &agentPruneOptions{}
+
+// parseImages parses images in the format [docker:|appc:]name, docker being the default
+func parseImages(names []string) []mesos.Image {
+	images := []mesos.Image{}
+	for _, name := range names {
+		switch {
+		case strings.HasPrefix(name, "appc:"):
+			images = append(images, mesos.Image{
+				Type: mesos.Image_APPC.Enum(),
+				Appc: &mesos.Image_Appc{Name: strings.TrimPrefix(name, "appc:")},
+			})
+		default:
+			images = append(images, mesos.Image{
+				Type:   mesos.Image_DOCKER.Enum(),
+				Docker: &mesos.Image_Docker{Name: strings.TrimPrefix(name, "docker:")},
+			})
+		}
+	}
+	return images
+}
+
+// pruneImages removes the unused images of the agent provisioner store, except the excluded ones
+func pruneImages(cli calls.Sender, excluded []mesos.Image) error {
+	call := &agent.Call{
+		Type:        agent.Call_PRUNE_IMAGES,
+		PruneImages: &agent.Call_PruneImages{ExcludedImages: excluded},
+	}
+	return sendAgentCommand(cli, call)
+}
+
+var agentPruneCalls = AgentCallsDef{
+	"images": AgentCallDef{
+		desc: "Prunes the images of the container image provisioner store not used by containers.",
+	},
+}
+
+var agentPruneCmd = &cobra.Command{
+	Use:     "prune [call]",
+	Short:   "Prune on agent",
+	Example: "prune images --exclude mesosphere/mesos-slave:latest",
+	Long:    agentPruneCalls.describeCalls(),
+	Args:    agentPruneCalls.validateArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return pruneImages(agentCli, parseImages(agentPruneOpts.exclude))
+	},
+}
+
+func init() {
+	agentCmd.AddCommand(agentPruneCmd)
+	agentPruneCmd.Flags().StringSliceVar(&agentPruneOpts.exclude, "exclude", []string{}, "images to keep, as [docker:|appc:]name (default docker)")
+
+	agentPruneCmd.SetUsageTemplate(agentSubCommandUsageTemplate)
+}
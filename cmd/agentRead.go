@@ -16,32 +16,20 @@ limitations under the License.
 package cmd
 
 import (
-	"context"
-	"fmt"
-	"strings"
+	"os"
 
-	"github.com/mesos/mesos-go/api/v1/lib/agent"
 	"github.com/mesos/mesos-go/api/v1/lib/agent/calls"
 	"github.com/spf13/cobra"
 )
 
 type agentReadOptions struct {
-	path   string
-	length uint64
-	offset uint64
+	readFileOptions
 }
 
 var agentReadOpts = &agentReadOptions{}
 
 var agentReadCalls = AgentCallsDef{
 	"file": AgentCallDef{
-		call: func() *agent.Call {
-			if agentReadOpts.length > 0 {
-				return calls.ReadFile(agentReadOpts.path, agentReadOpts.offset)
-			} else {
-				return calls.ReadFileWithLength(agentReadOpts.path, agentReadOpts.offset, agentReadOpts.length)
-			}
-		},
 		desc: "Reads data from a file on the agent.",
 	},
 }
@@ -53,30 +41,20 @@ var agentReadCmd = &cobra.Command{
 	Long:  agentReadCalls.describeCalls(),
 	Args:  agentReadCalls.validateArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		key := strings.Join(args, " ")
-		resp, err := agentCli.Send(context.Background(), calls.NonStreaming(agentReadCalls[key].call()))
-		defer func() {
-			if resp != nil {
-				resp.Close()
+		read := func(path string, offset uint64, length uint64) ([]byte, uint64, error) {
+			r, err := sendAgentQuery(agentCli, calls.ReadFileWithLength(path, offset, length))
+			if err != nil {
+				return nil, 0, err
 			}
-		}()
-		if err != nil {
-			return fmt.Errorf("Error sending call: %s", err)
-		}
-		var e agent.Response
-		err = resp.Decode(&e)
-		if err != nil {
-			return fmt.Errorf("Error decoding response: %s", err)
+			return r.GetReadFile().GetData(), r.GetReadFile().GetSize(), nil
 		}
-		return nil
+		return readFile(read, agentReadOpts.readFileOptions, os.Stdout)
 	},
 }
 
 func init() {
 	agentCmd.AddCommand(agentReadCmd)
-	agentReadCmd.Flags().StringVar(&agentReadOpts.path, "path", "", "path to list files")
-	agentReadCmd.Flags().Uint64Var(&agentReadOpts.offset, "offset", 0, "the offset to start reading")
-	agentReadCmd.Flags().Uint64Var(&agentReadOpts.length, "length", 0, "the maximum number of bytes to read")
+	addReadFileFlags(agentReadCmd, &agentReadOpts.readFileOptions)
 
 	agentReadCmd.SetUsageTemplate(agentSubCommandUsageTemplate)
 
@@ -0,0 +1,187 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/agent"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type agentResourceProviderOptions struct {
+	file   string
+	typ    string
+	name   string
+	json   bool
+	output string
+}
+
+var agentResourceProviderOpts = agentResourceProviderOptions{}
+
+// readResourceProviderInfo reads the ResourceProviderInfo of --file
+func readResourceProviderInfo() (*mesos.ResourceProviderInfo, error) {
+	if agentResourceProviderOpts.file == "" {
+		return nil, fmt.Errorf("Missing resource provider file in -f")
+	}
+	info := mesos.ResourceProviderInfo{}
+	if err := readJSONOrYAML(agentResourceProviderOpts.file, &info); err != nil {
+		return nil, err
+	}
+	if info.Type == "" || info.Name == "" {
+		return nil, fmt.Errorf("Resource provider type and name are required")
+	}
+	return &info, nil
+}
+
+var agentResourceProviderCmd = &cobra.Command{
+	Use:   "resource-provider",
+	Short: "List, add, update or remove local resource providers",
+	Long: `List, add, update or remove local resource providers.
+
+Resource providers are described by a ResourceProviderInfo YAML or JSON file, example:
+
+  type: org.apache.mesos.rp.local.storage
+  name: lvm
+  default_reservations:
+  - type: DYNAMIC
+    role: storage
+  storage:
+    plugin:
+      type: org.apache.mesos.csi.lvm
+      name: lvm
+      containers:
+      - services: [CONTROLLER_SERVICE, NODE_SERVICE]
+        command:
+          shell: true
+          value: ./csi-lvm-plugin --volume-group=vg`,
+}
+
+var agentResourceProviderListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List resource providers",
+	Example: "resource-provider list",
+	Long:    "List the resource providers of the agent with their total resources (GET_RESOURCE_PROVIDERS).",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, tmpl, err := parseOutputFlags(agentResourceProviderOpts.output, agentResourceProviderOpts.json)
+		if err != nil {
+			return err
+		}
+		r, err := sendAgentQuery(agentCli, &agent.Call{Type: agent.Call_GET_RESOURCE_PROVIDERS})
+		if err != nil {
+			return err
+		}
+		providers := r.GetGetResourceProviders().GetResourceProviders()
+		if !isTableOutput(format) {
+			return printJSON(providers, format, tmpl)
+		}
+		table := newTable(format)
+		table.SetHeader([]string{"id", "type", "name", "resources"})
+		for _, p := range providers {
+			info := p.GetResourceProviderInfo()
+			table.Append([]string{
+				info.GetID().GetValue(),
+				info.GetType(),
+				info.GetName(),
+				mesos.Resources(p.GetTotalResources()).String(),
+			})
+		}
+		table.SetBorder(false)
+		table.SetHeaderLine(false)
+		table.SetColumnSeparator("")
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.Render()
+		return nil
+	},
+}
+
+var agentResourceProviderAddCmd = &cobra.Command{
+	Use:     "add",
+	Short:   "Add a resource provider",
+	Example: "resource-provider add -f lvm.yaml",
+	Long:    "Add a resource provider config (ADD_RESOURCE_PROVIDER_CONFIG).",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := readResourceProviderInfo()
+		if err != nil {
+			return err
+		}
+		return sendAgentCommand(agentCli, &agent.Call{
+			Type:                      agent.Call_ADD_RESOURCE_PROVIDER_CONFIG,
+			AddResourceProviderConfig: &agent.Call_AddResourceProviderConfig{Info: *info},
+		})
+	},
+}
+
+var agentResourceProviderUpdateCmd = &cobra.Command{
+	Use:     "update",
+	Short:   "Update a resource provider",
+	Example: "resource-provider update -f lvm.yaml",
+	Long:    "Update the config of the resource provider with the same type and name (UPDATE_RESOURCE_PROVIDER_CONFIG).",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := readResourceProviderInfo()
+		if err != nil {
+			return err
+		}
+		return sendAgentCommand(agentCli, &agent.Call{
+			Type:                         agent.Call_UPDATE_RESOURCE_PROVIDER_CONFIG,
+			UpdateResourceProviderConfig: &agent.Call_UpdateResourceProviderConfig{Info: *info},
+		})
+	},
+}
+
+var agentResourceProviderRemoveCmd = &cobra.Command{
+	Use:     "remove",
+	Short:   "Remove a resource provider",
+	Example: "resource-provider remove --type org.apache.mesos.rp.local.storage --name lvm",
+	Long: `Remove a resource provider config (REMOVE_RESOURCE_PROVIDER_CONFIG).
+
+The resource provider is stopped, and should then be marked gone on the master.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if agentResourceProviderOpts.typ == "" || agentResourceProviderOpts.name == "" {
+			return fmt.Errorf("Missing resource provider --type or --name")
+		}
+		return sendAgentCommand(agentCli, &agent.Call{
+			Type: agent.Call_REMOVE_RESOURCE_PROVIDER_CONFIG,
+			RemoveResourceProviderConfig: &agent.Call_RemoveResourceProviderConfig{
+				Type: agentResourceProviderOpts.typ,
+				Name: agentResourceProviderOpts.name,
+			},
+		})
+	},
+}
+
+func init() {
+	agentCmd.AddCommand(agentResourceProviderCmd)
+	agentResourceProviderCmd.AddCommand(agentResourceProviderListCmd, agentResourceProviderAddCmd, agentResourceProviderUpdateCmd, agentResourceProviderRemoveCmd)
+	agentResourceProviderListCmd.Flags().BoolVar(&agentResourceProviderOpts.json, "json", false, "output resource providers in JSON (same as -o json)")
+	addOutputFlag(agentResourceProviderListCmd, &agentResourceProviderOpts.output)
+	for _, c := range []*cobra.Command{agentResourceProviderAddCmd, agentResourceProviderUpdateCmd} {
+		c.Flags().StringVarP(&agentResourceProviderOpts.file, "file", "f", "", "YAML or JSON ResourceProviderInfo file")
+	}
+	agentResourceProviderRemoveCmd.Flags().StringVar(&agentResourceProviderOpts.typ, "type", "", "resource provider type")
+	agentResourceProviderRemoveCmd.Flags().StringVar(&agentResourceProviderOpts.name, "name", "", "resource provider name")
+
+	agentResourceProviderCmd.SetUsageTemplate(agentSubCommandUsageTemplate)
+	for _, c := range agentResourceProviderCmd.Commands() {
+		c.SetUsageTemplate(agentNestedSubCommandUsageTemplate)
+	}
+}
@@ -0,0 +1,303 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/agent/calls"
+	"github.com/spf13/cobra"
+)
+
+type cpOptions struct {
+	recursive bool
+	parallel  int
+	tar       bool
+}
+
+var cpOpts = cpOptions{}
+
+// remoteFile is a file or directory to copy, with its path relative to the copied directory
+type remoteFile struct {
+	info mesos.FileInfo
+	rel  string
+}
+
+// walkFiles returns the file at src, or, if recursive, the directory at src and all its
+// files and subdirectories (LIST_FILES)
+func walkFiles(cli calls.Sender, src string, recursive bool) ([]remoteFile, error) {
+	list := func(dir string) ([]mesos.FileInfo, error) {
+		r, err := sendAgentQuery(cli, calls.ListFiles(dir))
+		if err != nil {
+			return nil, fmt.Errorf("Error listing %s: %s", dir, err)
+		}
+		return r.GetListFiles().GetFileInfos(), nil
+	}
+	files, err := list(src)
+	if err != nil {
+		return nil, err
+	}
+	// listing a file returns the file itself
+	if len(files) == 1 && files[0].Path == src && !isDir(files[0]) {
+		return []remoteFile{{info: files[0], rel: path.Base(src)}}, nil
+	}
+	if !recursive {
+		return nil, fmt.Errorf("%s is a directory (use -r)", src)
+	}
+
+	base := path.Base(src)
+	mode := uint32(modeDir | 0755)
+	walked := []remoteFile{{info: mesos.FileInfo{Path: src, Mode: &mode}, rel: base}}
+	dirs := [][]mesos.FileInfo{files}
+	prefixes := []string{base}
+	for len(dirs) > 0 {
+		files, prefix := dirs[0], prefixes[0]
+		dirs, prefixes = dirs[1:], prefixes[1:]
+		for _, f := range files {
+			rel := path.Join(prefix, path.Base(f.Path))
+			walked = append(walked, remoteFile{info: f, rel: rel})
+			if isDir(f) {
+				sub, err := list(f.Path)
+				if err != nil {
+					return nil, err
+				}
+				dirs = append(dirs, sub)
+				prefixes = append(prefixes, rel)
+			}
+		}
+	}
+	return walked, nil
+}
+
+// agentFileReader returns a function reading files of the agent (READ_FILE)
+func agentFileReader(cli calls.Sender) readFileFunc {
+	return func(filePath string, offset uint64, length uint64) ([]byte, uint64, error) {
+		r, err := sendAgentQuery(cli, calls.ReadFileWithLength(filePath, offset, length))
+		if err != nil {
+			return nil, 0, err
+		}
+		return r.GetReadFile().GetData(), r.GetReadFile().GetSize(), nil
+	}
+}
+
+// downloadFile copies the remote file to dst. If dst is smaller than the remote file,
+// the download resumes at the end of dst, if it has the same size it is skipped.
+// The mode of the remote file is set once downloaded, so that read only files can be resumed.
+func downloadFile(cli calls.Sender, f mesos.FileInfo, dst string) error {
+	flags := os.O_WRONLY | os.O_CREATE
+	offset := uint64(0)
+	if stat, err := os.Stat(dst); err == nil {
+		switch {
+		case uint64(stat.Size()) == f.GetSize():
+			if verbose {
+				fmt.Fprintf(os.Stderr, "Skipping %s, already downloaded\n", dst)
+			}
+			return nil
+		case uint64(stat.Size()) < f.GetSize():
+			offset = uint64(stat.Size())
+			flags |= os.O_APPEND
+		default:
+			flags |= os.O_TRUNC
+		}
+		if err = os.Chmod(dst, 0644); err != nil {
+			return err
+		}
+	}
+	out, err := os.OpenFile(dst, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Downloading %s to %s from offset %d\n", f.Path, dst, offset)
+	}
+	if f.GetSize() > offset {
+		err = readFile(agentFileReader(cli), readFileOptions{
+			path:      f.Path,
+			offset:    offset,
+			length:    f.GetSize() - offset,
+			tailLines: -1,
+			tailBytes: -1,
+		}, out)
+		if err != nil {
+			return err
+		}
+	}
+	if err = os.Chmod(dst, os.FileMode(f.GetMode()&0777)); err != nil {
+		return err
+	}
+	mtime := time.Unix(0, f.GetMtime().GetNanoseconds())
+	return os.Chtimes(dst, mtime, mtime)
+}
+
+// skipSymlink warns that a symlink is not copied, as LIST_FILES does not return its target
+func skipSymlink(f remoteFile) {
+	fmt.Fprintf(os.Stderr, "Skipping symlink %s\n", f.info.Path)
+}
+
+// download copies the files to the local dst directory, at most --parallel files at once
+func download(cli calls.Sender, files []remoteFile, dst string) error {
+	for _, f := range files {
+		if isDir(f.info) {
+			if err := os.MkdirAll(filepath.Join(dst, filepath.FromSlash(f.rel)), 0755); err != nil {
+				return err
+			}
+		}
+	}
+	errs := make([]error, len(files))
+	slots := make(chan bool, cpOpts.parallel)
+	var wg sync.WaitGroup
+	for i, f := range files {
+		if isDir(f.info) {
+			continue
+		}
+		if f.info.GetMode()&modeTypeMask == modeSymlink {
+			skipSymlink(f)
+			continue
+		}
+		wg.Add(1)
+		slots <- true
+		go func(i int, f remoteFile) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			errs[i] = downloadFile(cli, f.info, filepath.Join(dst, filepath.FromSlash(f.rel)))
+		}(i, f)
+	}
+	wg.Wait()
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", files[i].info.Path, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Failed to download %d files", failed)
+	}
+	return nil
+}
+
+// writeTar writes the files as a tar archive to stdout
+func writeTar(cli calls.Sender, files []remoteFile) error {
+	tw := tar.NewWriter(os.Stdout)
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    f.rel,
+			Mode:    int64(f.info.GetMode() & 0777),
+			Uname:   f.info.GetUID(),
+			Gname:   f.info.GetGID(),
+			ModTime: time.Unix(0, f.info.GetMtime().GetNanoseconds()),
+		}
+		switch {
+		case isDir(f.info):
+			header.Typeflag = tar.TypeDir
+			header.Name += "/"
+		case f.info.GetMode()&modeTypeMask == modeSymlink:
+			skipSymlink(f)
+			continue
+		default:
+			header.Typeflag = tar.TypeReg
+			header.Size = int64(f.info.GetSize())
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg && header.Size > 0 {
+			err := readFile(agentFileReader(cli), readFileOptions{
+				path:      f.info.Path,
+				length:    f.info.GetSize(),
+				tailLines: -1,
+				tailBytes: -1,
+			}, tw)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Close()
+}
+
+var cpCmd = &cobra.Command{
+	Use: "cp [flags] [agent]:[path] [local path]",
+	Example: `cp mesos-agent123:/var/lib/mesos/slaves/.../runs/latest/heap.hprof .
+cp -r mesos-agent123:/var/lib/mesos/slaves/.../runs/latest ./sandbox
+cp -r --tar mesos-agent123:/var/lib/mesos/slaves/.../runs/latest | tar tv`,
+	Short: "Copy files from an agent",
+	Long: `Copy a file, or a directory with -r, from an agent to the local machine (LIST_FILES and READ_FILE).
+
+Agent is specified like for the agent command. Like cp, the file or directory is copied
+into the local path if it is a directory, or to the local path otherwise.
+
+Files already present locally are resumed if they are smaller than the remote file, and
+skipped if they have the same size. With --tar, a tar archive is written to stdout instead.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cpOpts.tar {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		i := strings.Index(args[0], ":/")
+		if i <= 0 {
+			return fmt.Errorf("Expected [agent]:[path] with an absolute path, got %s", args[0])
+		}
+		src := strings.TrimSuffix(args[0][i+1:], "/")
+		if src == "" {
+			return fmt.Errorf("Cannot copy the root directory of agent %s", args[0][:i])
+		}
+		if cpOpts.parallel < 1 {
+			return fmt.Errorf("--parallel must be at least 1")
+		}
+		cli, err := resolveAgentCli(args[0][:i])
+		if err != nil {
+			return err
+		}
+		files, err := walkFiles(cli, src, cpOpts.recursive)
+		if err != nil {
+			return err
+		}
+		if cpOpts.tar {
+			return writeTar(cli, files)
+		}
+
+		dst := args[1]
+		if stat, err := os.Stat(dst); err != nil || !stat.IsDir() {
+			// copy to dst instead of into it
+			for i := range files {
+				files[i].rel = strings.TrimPrefix(files[i].rel, path.Base(src))
+			}
+		}
+		return download(cli, files, dst)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+	cpCmd.Flags().BoolVarP(&cpOpts.recursive, "recursive", "r", false, "copy directories recursively")
+	cpCmd.Flags().IntVar(&cpOpts.parallel, "parallel", 4, "number of files to download at once")
+	cpCmd.Flags().BoolVar(&cpOpts.tar, "tar", false, "write a tar archive to stdout")
+}
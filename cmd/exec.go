@@ -0,0 +1,252 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/agent/calls"
+	masterCalls "github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/spf13/cobra"
+)
+
+type execOptions struct {
+	url         string
+	tty         bool
+	interactive bool
+	selector    string
+	parallel    int
+}
+
+var execOpts = execOptions{}
+
+// prefixWriter writes the lines prefixed, each line being written at once to the shared
+// writer so that lines of concurrent writers are not mixed
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	mutex  *sync.Mutex
+	buffer []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	for {
+		i := bytes.IndexByte(w.buffer, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.writeLine(w.buffer[:i+1])
+		w.buffer = w.buffer[i+1:]
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	fmt.Fprintf(w.out, "%s: %s", w.prefix, line)
+}
+
+// flush writes the last line if it does not end with a newline
+func (w *prefixWriter) flush() {
+	if len(w.buffer) > 0 {
+		w.writeLine(append(w.buffer, '\n'))
+		w.buffer = nil
+	}
+}
+
+// execTask runs the command in a nested container of the task, writing its output to stdout
+// and stderr, and returns its exit status
+func execTask(cli calls.Sender, task mesos.Task, command []string, stdout io.Writer, stderr io.Writer) (*int32, error) {
+	parent, err := taskContainerID(cli, task)
+	if err != nil {
+		return nil, err
+	}
+	containerId := mesos.ContainerID{Value: uuid.New().String(), Parent: parent}
+	commandInfo, containerInfo := newLaunchInfos(command, false)
+	resp, err := cli.Send(context.Background(), calls.NonStreaming(calls.LaunchNestedContainerSession(containerId, commandInfo, containerInfo)))
+	defer func() {
+		if resp != nil {
+			resp.Close()
+		}
+	}()
+	if err != nil {
+		return nil, fmt.Errorf("Error sending call: %s", err)
+	}
+	if err = processOutput(resp, stdout, stderr); err != nil {
+		return nil, err
+	}
+	return waitContainer(cli, containerId)
+}
+
+type execResult struct {
+	task   string
+	status *int32
+	err    error
+}
+
+// execSelected runs the command in the tasks matching the selector, at most --parallel at once,
+// and prints a summary of the failures
+func execSelected(cli masterCalls.Sender, command []string) error {
+	selector, err := parseSelector(execOpts.selector)
+	if err != nil {
+		return err
+	}
+	tasks, agents, err := selectTasks(cli, selector)
+	if err != nil {
+		return err
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].TaskID.Value < tasks[j].TaskID.Value
+	})
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Executing in %d tasks\n", len(tasks))
+	}
+
+	results := make([]execResult, len(tasks))
+	mutex := &sync.Mutex{}
+	slots := make(chan bool, execOpts.parallel)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		slots <- true
+		go func(i int, task mesos.Task) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			results[i].task = task.TaskID.Value
+			a, ok := agents[task.AgentID.Value]
+			if !ok {
+				results[i].err = fmt.Errorf("Agent %s is not registered", task.AgentID.Value)
+				return
+			}
+			taskCli, err := registeredAgentCli(a.GetAgentInfo())
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			stdout := &prefixWriter{prefix: task.TaskID.Value, out: os.Stdout, mutex: mutex}
+			stderr := &prefixWriter{prefix: task.TaskID.Value, out: os.Stderr, mutex: mutex}
+			results[i].status, results[i].err = execTask(taskCli, task, command, stdout, stderr)
+			stdout.flush()
+			stderr.flush()
+		}(i, task)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			fmt.Fprintf(os.Stderr, "%s: error: %s\n", r.task, r.err)
+		case r.status == nil:
+			fmt.Fprintf(os.Stderr, "%s: no exit status\n", r.task)
+		case exitCode(*r.status) != 0:
+			fmt.Fprintf(os.Stderr, "%s: exit code %d\n", r.task, exitCode(*r.status))
+		default:
+			continue
+		}
+		failed++
+	}
+	if failed > 0 {
+		return fmt.Errorf("Command failed in %d of %d tasks", failed, len(tasks))
+	}
+	return nil
+}
+
+var execCmd = &cobra.Command{
+	Use: "exec [flags] [task-id] -- [command]",
+	Example: `exec -ti observability_test.dacaab82 -- bash
+exec --selector framework=marathon,task=web.* -- cat /etc/hosts`,
+	Short: "Execute a command in task containers",
+	Long: `Execute a command in a nested container of a running task, like kubectl exec.
+
+Task is a task id prefix. The task is looked up on the master (GET_TASKS), then its
+container on the agent running it (GET_CONTAINERS), and the command is launched in a
+nested container session of the task container.
+
+With --selector, the command is executed non interactively in all the running tasks
+matching the selector, --parallel at once. Each output line is prefixed with the task id
+and the tasks where the command failed are listed at the end. The selector is a list of
+key=regex, where key is one of framework (name or id), task (id) or agent (hostname or id).
+Regular expressions must match the whole value and may contain commas.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if execOpts.selector != "" {
+			if cmd.ArgsLenAtDash() != 0 || len(args) < 1 {
+				return fmt.Errorf("Expected -- followed by the command")
+			}
+			return nil
+		}
+		if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+			return fmt.Errorf("Expected a task id followed by -- and the command")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url, err := masterURL(execOpts.url)
+		if err != nil {
+			return err
+		}
+		cli := newMasterCli(url)
+		if execOpts.selector != "" {
+			if execOpts.interactive || execOpts.tty {
+				return fmt.Errorf("Commands executed with --selector cannot be interactive")
+			}
+			if execOpts.parallel < 1 {
+				return fmt.Errorf("--parallel must be at least 1")
+			}
+			return execSelected(cli, args)
+		}
+		task, err := findTask(cli, args[0])
+		if err != nil {
+			return err
+		}
+		taskCli, err := taskAgentCli(cli, *task)
+		if err != nil {
+			return err
+		}
+		parent, err := taskContainerID(taskCli, *task)
+		if err != nil {
+			return err
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Task %s runs in container %s\n", task.TaskID.Value, formatContainerID(*parent))
+		}
+
+		containerId := mesos.ContainerID{Value: uuid.New().String(), Parent: parent}
+		commandInfo, containerInfo := newLaunchInfos(args[1:], execOpts.tty)
+		call := calls.LaunchNestedContainerSession(containerId, commandInfo, containerInfo)
+		return runSession(taskCli, call, containerId, execOpts.interactive, execOpts.tty)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().StringVarP(&execOpts.url, "url", "u", "", "Mesos master URL (default master.url configuration)")
+	execCmd.Flags().BoolVarP(&execOpts.tty, "tty", "t", false, "enable TTY")
+	execCmd.Flags().BoolVarP(&execOpts.interactive, "interactive", "i", false, "interactive run (handle STDIN, type ~? after a newline for escape sequences)")
+	execCmd.Flags().StringVar(&execOpts.selector, "selector", "", "execute in all running tasks matching the selector in the format 'key=regex[,key=regex...]' (see --help)")
+	execCmd.Flags().IntVar(&execOpts.parallel, "parallel", 10, "number of tasks to execute in at once with --selector")
+}
@@ -0,0 +1,300 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// listFilesOptions are the options of list files, shared by master and agent
+type listFilesOptions struct {
+	json          bool
+	output        string
+	sort          string
+	humanReadable bool
+	recursive     bool
+}
+
+// listFilesFunc lists the files of a directory with a LIST_FILES call
+type listFilesFunc func(path string) ([]mesos.FileInfo, error)
+
+func addListFilesFlags(cmd *cobra.Command, opts *listFilesOptions) {
+	cmd.Flags().BoolVar(&opts.json, "json", false, "output files in JSON (same as -o json)")
+	addOutputFlag(cmd, &opts.output)
+	cmd.Flags().StringVar(&opts.sort, "sort", "name", "sort files by name, size or mtime")
+	cmd.Flags().BoolVar(&opts.humanReadable, "human-readable", false, "print sizes like 1K 234M 2G")
+	cmd.Flags().BoolVarP(&opts.recursive, "recursive", "R", false, "list subdirectories recursively")
+}
+
+const (
+	modeTypeMask = 0170000
+	modeDir      = 0040000
+	modeSymlink  = 0120000
+)
+
+// isDir returns true if the file mode (st_mode) is the one of a directory
+func isDir(f mesos.FileInfo) bool {
+	return f.GetMode()&modeTypeMask == modeDir
+}
+
+// formatMode formats the file mode (st_mode) like ls -l
+func formatMode(mode uint32) string {
+	m := os.FileMode(mode & 0777)
+	switch mode & modeTypeMask {
+	case modeDir:
+		m |= os.ModeDir
+	case modeSymlink:
+		m |= os.ModeSymlink
+	}
+	return m.String()
+}
+
+// formatSize formats the size in bytes, or with a unit like 1.5K if human readable
+func formatSize(size uint64, humanReadable bool) string {
+	if !humanReadable || size < 1024 {
+		return strconv.FormatUint(size, 10)
+	}
+	value := float64(size)
+	unit := 0
+	units := "KMGTPE"
+	for value /= 1024; value >= 1024 && unit < len(units)-1; value /= 1024 {
+		unit++
+	}
+	if value < 10 {
+		return fmt.Sprintf("%.1f%c", value, units[unit])
+	}
+	return fmt.Sprintf("%.0f%c", value, units[unit])
+}
+
+func sortFiles(files []mesos.FileInfo, by string) error {
+	var less func(i, j int) bool
+	switch by {
+	case "name":
+		less = func(i, j int) bool { return files[i].Path < files[j].Path }
+	case "size":
+		less = func(i, j int) bool { return files[i].GetSize() > files[j].GetSize() }
+	case "mtime":
+		less = func(i, j int) bool {
+			return files[i].GetMtime().GetNanoseconds() > files[j].GetMtime().GetNanoseconds()
+		}
+	default:
+		return fmt.Errorf("Unknown sort %s, expected name, size or mtime", by)
+	}
+	sort.SliceStable(files, less)
+	return nil
+}
+
+func printFiles(files []mesos.FileInfo, humanReadable bool, format string) {
+	table := newTable(format)
+	table.SetHeader([]string{"mode", "nlink", "uid", "gid", "size", "mtime", "path"})
+	for _, f := range files {
+		table.Append([]string{
+			formatMode(f.GetMode()),
+			strconv.Itoa(int(f.GetNlink())),
+			f.GetUID(),
+			f.GetGID(),
+			formatSize(f.GetSize(), humanReadable),
+			time.Unix(0, f.GetMtime().GetNanoseconds()).Format("Jan _2 15:04"),
+			f.Path,
+		})
+	}
+	table.SetBorder(false)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
+
+// listFiles lists the files of path like ls -l, walking the subdirectories if recursive.
+// In other formats than table, the files of all the directories are output in a single list.
+func listFiles(list listFilesFunc, path string, opts listFilesOptions) error {
+	format, tmpl, err := parseOutputFlags(opts.output, opts.json)
+	if err != nil {
+		return err
+	}
+	all := []mesos.FileInfo{}
+	dirs := []string{path}
+	for len(dirs) > 0 {
+		dir := dirs[0]
+		dirs = dirs[1:]
+		files, err := list(dir)
+		if err != nil {
+			return fmt.Errorf("Error listing %s: %s", dir, err)
+		}
+		if err = sortFiles(files, opts.sort); err != nil {
+			return err
+		}
+		if opts.recursive {
+			subdirs := []string{}
+			for _, f := range files {
+				if isDir(f) {
+					subdirs = append(subdirs, f.Path)
+				}
+			}
+			// depth first, like ls -R
+			dirs = append(subdirs, dirs...)
+		}
+		if format != "table" {
+			all = append(all, files...)
+			continue
+		}
+		if opts.recursive {
+			fmt.Printf("%s:\n", dir)
+		}
+		printFiles(files, opts.humanReadable, format)
+		if opts.recursive && len(dirs) > 0 {
+			fmt.Println()
+		}
+	}
+	switch {
+	case format == "table":
+		return nil
+	case isTableOutput(format):
+		printFiles(all, opts.humanReadable, format)
+		return nil
+	default:
+		return printJSON(all, format, tmpl)
+	}
+}
+
+// readFileChunk is the maximum number of bytes read at once with READ_FILE
+const readFileChunk = 1024 * 1024
+
+// readFileOptions are the options of read file, shared by master and agent
+type readFileOptions struct {
+	path      string
+	offset    uint64
+	length    uint64
+	tailLines int64
+	tailBytes int64
+	follow    bool
+	interval  time.Duration
+}
+
+// readFileFunc reads length bytes of the file from offset with a READ_FILE call,
+// returning the data read and the current size of the file
+type readFileFunc func(path string, offset uint64, length uint64) ([]byte, uint64, error)
+
+func addReadFileFlags(cmd *cobra.Command, opts *readFileOptions) {
+	cmd.Flags().StringVar(&opts.path, "path", "", "path of the file to read")
+	cmd.Flags().Uint64Var(&opts.offset, "offset", 0, "the offset to start reading")
+	cmd.Flags().Uint64Var(&opts.length, "length", 0, "the maximum number of bytes to read (default until the end)")
+	cmd.Flags().Int64Var(&opts.tailLines, "tail", -1, "read the last N lines of the file (overrides --offset)")
+	cmd.Flags().Int64Var(&opts.tailBytes, "tail-bytes", -1, "read the last N bytes of the file (overrides --offset)")
+	cmd.Flags().BoolVarP(&opts.follow, "follow", "f", false, "wait for data appended to the file, like tail -f")
+	cmd.Flags().DurationVar(&opts.interval, "interval", time.Second, "interval to poll for data with --follow")
+}
+
+// tailOffset returns the offset of the last lines of the file, reading it backwards by chunks
+// until enough newlines are found. The newline ending the file does not start a line.
+func tailOffset(read readFileFunc, path string, lines uint64) (uint64, error) {
+	_, size, err := read(path, 0, 1)
+	if err != nil {
+		return 0, err
+	}
+	if lines == 0 {
+		return size, nil
+	}
+	found := uint64(0)
+	end := size
+	for end > 0 {
+		start := uint64(0)
+		if end > readFileChunk {
+			start = end - readFileChunk
+		}
+		data, _, err := read(path, start, end-start)
+		if err != nil {
+			return 0, err
+		}
+		if uint64(len(data)) != end-start {
+			return 0, fmt.Errorf("file changed while being read")
+		}
+		for i := len(data) - 1; i >= 0; i-- {
+			if data[i] != '\n' || start+uint64(i) == size-1 {
+				continue
+			}
+			found++
+			if found == lines {
+				return start + uint64(i) + 1, nil
+			}
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// readFile writes the file to out by chunks, then polls for new data if follow is set
+func readFile(read readFileFunc, opts readFileOptions, out io.Writer) error {
+	offset := opts.offset
+	switch {
+	case opts.tailLines >= 0:
+		var err error
+		if offset, err = tailOffset(read, opts.path, uint64(opts.tailLines)); err != nil {
+			return fmt.Errorf("Error reading %s: %s", opts.path, err)
+		}
+	case opts.tailBytes >= 0:
+		_, size, err := read(opts.path, 0, 1)
+		if err != nil {
+			return fmt.Errorf("Error reading %s: %s", opts.path, err)
+		}
+		offset = 0
+		if size > uint64(opts.tailBytes) {
+			offset = size - uint64(opts.tailBytes)
+		}
+	}
+	remaining := opts.length
+	for {
+		length := uint64(readFileChunk)
+		if opts.length > 0 && remaining < length {
+			length = remaining
+		}
+		data, size, err := read(opts.path, offset, length)
+		if err != nil {
+			return fmt.Errorf("Error reading %s: %s", opts.path, err)
+		}
+		if _, err = out.Write(data); err != nil {
+			return err
+		}
+		offset += uint64(len(data))
+		if opts.length > 0 {
+			remaining -= uint64(len(data))
+			if remaining == 0 {
+				return nil
+			}
+		}
+		if len(data) > 0 && offset < size {
+			continue
+		}
+		if !opts.follow {
+			return nil
+		}
+		if size < offset {
+			fmt.Fprintf(os.Stderr, "%s: file truncated\n", opts.path)
+			offset = 0
+			continue
+		}
+		time.Sleep(opts.interval)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeFileReader returns a function reading data like READ_FILE does
+func fakeFileReader(data string) readFileFunc {
+	return func(path string, offset uint64, length uint64) ([]byte, uint64, error) {
+		size := uint64(len(data))
+		if offset > size {
+			return nil, size, nil
+		}
+		end := offset + length
+		if end > size {
+			end = size
+		}
+		return []byte(data[offset:end]), size, nil
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	big := strings.Repeat("0123456789", readFileChunk/4)
+	lines := strings.Repeat("123456789\n", readFileChunk/4)
+	tests := []struct {
+		data string
+		opts readFileOptions
+		want string
+	}{
+		{"hello world", readFileOptions{tailLines: -1, tailBytes: -1}, "hello world"},
+		{"hello world", readFileOptions{offset: 6, tailLines: -1, tailBytes: -1}, "world"},
+		{"hello world", readFileOptions{offset: 2, length: 3, tailLines: -1, tailBytes: -1}, "llo"},
+		{"hello world", readFileOptions{offset: 6, length: 100, tailLines: -1, tailBytes: -1}, "world"},
+		{"hello world", readFileOptions{offset: 100, tailLines: -1, tailBytes: -1}, ""},
+		{"hello world", readFileOptions{tailLines: -1, tailBytes: 5}, "world"},
+		{"hello world", readFileOptions{offset: 2, tailLines: -1, tailBytes: 5}, "world"},
+		{"hello world", readFileOptions{tailLines: -1, tailBytes: 100}, "hello world"},
+		{"hello world", readFileOptions{tailLines: -1, tailBytes: 0}, ""},
+		{"", readFileOptions{tailLines: -1, tailBytes: -1}, ""},
+		{"a\nb\nc\n", readFileOptions{tailLines: 2, tailBytes: -1}, "b\nc\n"},
+		{"a\nb\nc", readFileOptions{tailLines: 2, tailBytes: -1}, "b\nc"},
+		{"a\nb\nc\n", readFileOptions{tailLines: 10, tailBytes: -1}, "a\nb\nc\n"},
+		{"a\nb\nc\n", readFileOptions{tailLines: 0, tailBytes: -1}, ""},
+		{"a\n\nc\n", readFileOptions{tailLines: 2, tailBytes: -1}, "\nc\n"},
+		{"a\nb\nc\n", readFileOptions{offset: 1, tailLines: 1, tailBytes: 1}, "c\n"},
+		{"", readFileOptions{tailLines: 1, tailBytes: -1}, ""},
+		{big, readFileOptions{tailLines: -1, tailBytes: -1}, big},
+		{big, readFileOptions{offset: 10, length: readFileChunk + 5, tailLines: -1, tailBytes: -1}, big[10 : readFileChunk+15]},
+		{big, readFileOptions{tailLines: -1, tailBytes: readFileChunk + 5}, big[len(big)-readFileChunk-5:]},
+		{big, readFileOptions{tailLines: 1, tailBytes: -1}, big},
+		{lines, readFileOptions{tailLines: 2, tailBytes: -1}, "123456789\n123456789\n"},
+		{lines, readFileOptions{tailLines: readFileChunk/10 + 1, tailBytes: -1}, lines[len(lines)-(readFileChunk/10+1)*10:]},
+	}
+	for _, test := range tests {
+		out := bytes.Buffer{}
+		name := fmt.Sprintf("%d bytes with %+v", len(test.data), test.opts)
+		if err := readFile(fakeFileReader(test.data), test.opts, &out); err != nil {
+			t.Errorf("readFile of %s failed: %s", name, err)
+			continue
+		}
+		if out.String() != test.want {
+			t.Errorf("readFile of %s = %q, want %q", name, truncate(out.String()), truncate(test.want))
+		}
+	}
+}
+
+func truncate(s string) string {
+	if len(s) > 20 {
+		return s[:20] + "..."
+	}
+	return s
+}
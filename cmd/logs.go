@@ -0,0 +1,103 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/mesos/mesos-go/api/v1/lib/agent/calls"
+	"github.com/spf13/cobra"
+)
+
+type logsOptions struct {
+	url       string
+	stderr    bool
+	tailLines int64
+	tailBytes int64
+	follow    bool
+	interval  time.Duration
+}
+
+var logsOpts = logsOptions{}
+
+var logsCmd = &cobra.Command{
+	Use:     "logs [flags] [task-id]",
+	Example: "logs -f --tail 100 observability_test.dacaab82",
+	Short:   "Print the logs of a task",
+	Long: `Print the stdout (or stderr) file of the sandbox of a running task.
+
+Task is a task id prefix. The task is looked up on the master (GET_TASKS), then its
+sandbox on the agent running it, and the log file is read with READ_FILE.
+
+The v1 API does not expose the sandbox directory of executors, so it is built from the
+work_dir flag of the agent (GET_FLAGS) like the agent does. This assumes the default
+sandbox layout: <work_dir>/slaves/<agent>/frameworks/<framework>/executors/<executor>/runs/<container>.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url, err := masterURL(logsOpts.url)
+		if err != nil {
+			return err
+		}
+		cli := newMasterCli(url)
+		task, err := findTask(cli, args[0])
+		if err != nil {
+			return err
+		}
+		taskCli, err := taskAgentCli(cli, *task)
+		if err != nil {
+			return err
+		}
+		sandbox, err := taskSandbox(taskCli, *task)
+		if err != nil {
+			return err
+		}
+		file := "stdout"
+		if logsOpts.stderr {
+			file = "stderr"
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Reading %s\n", path.Join(sandbox, file))
+		}
+
+		read := func(filePath string, offset uint64, length uint64) ([]byte, uint64, error) {
+			r, err := sendAgentQuery(taskCli, calls.ReadFileWithLength(filePath, offset, length))
+			if err != nil {
+				return nil, 0, err
+			}
+			return r.GetReadFile().GetData(), r.GetReadFile().GetSize(), nil
+		}
+		return readFile(read, readFileOptions{
+			path:      path.Join(sandbox, file),
+			tailLines: logsOpts.tailLines,
+			tailBytes: logsOpts.tailBytes,
+			follow:    logsOpts.follow,
+			interval:  logsOpts.interval,
+		}, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().StringVarP(&logsOpts.url, "url", "u", "", "Mesos master URL (default master.url configuration)")
+	logsCmd.Flags().BoolVar(&logsOpts.stderr, "stderr", false, "print stderr instead of stdout")
+	logsCmd.Flags().Int64Var(&logsOpts.tailLines, "tail", -1, "print the last N lines of the log")
+	logsCmd.Flags().Int64Var(&logsOpts.tailBytes, "tail-bytes", -1, "print the last N bytes of the log")
+	logsCmd.Flags().BoolVarP(&logsOpts.follow, "follow", "f", false, "wait for new logs, like tail -f")
+	logsCmd.Flags().DurationVar(&logsOpts.interval, "interval", time.Second, "interval to poll for new logs with --follow")
+}
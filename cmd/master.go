@@ -16,6 +16,7 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -34,16 +35,7 @@ var masterCmd = &cobra.Command{
 	Short: "Interact with Mesos Master",
 	Long:  `Interact with Mesos Master`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		var auth httpcli.ConfigOpt
-		if viper.IsSet("principal") && viper.GetString("principal") != "" {
-			auth = httpcli.BasicAuth(
-				viper.GetString("principal"),
-				viper.GetString("secret"))
-		}
-		masterCli = httpmaster.NewSender(
-			httpcli.New(
-				httpcli.Endpoint(viper.GetString("master.url")+"/api/v1"),
-				httpcli.Do(httpcli.With(auth))).Send)
+		masterCli = newMasterCli(viper.GetString("master.url"))
 	},
 }
 
@@ -55,6 +47,98 @@ func init() {
 	masterCmd.MarkPersistentFlagRequired("url")
 }
 
+func newMasterCli(url string) calls.Sender {
+	var auth httpcli.ConfigOpt
+	if viper.IsSet("principal") && viper.GetString("principal") != "" {
+		auth = httpcli.BasicAuth(
+			viper.GetString("principal"),
+			viper.GetString("secret"))
+	}
+	return httpmaster.NewSender(
+		httpcli.New(
+			httpcli.Endpoint(url+"/api/v1"),
+			httpcli.Do(httpcli.With(auth))).Send)
+}
+
+// masterURL returns url if set (commands outside of master with their own --url),
+// or the master URL of the configuration
+func masterURL(url string) (string, error) {
+	if url == "" {
+		url = viper.GetString("master.url")
+	}
+	if url == "" {
+		return "", fmt.Errorf("Missing master URL in --url or master.url configuration")
+	}
+	return url, nil
+}
+
+// sendMasterCall sends a non streaming call and decodes its response
+func sendMasterCall(cli calls.Sender, call *master.Call) (*master.Response, error) {
+	resp, err := cli.Send(context.Background(), calls.NonStreaming(call))
+	defer func() {
+		if resp != nil {
+			resp.Close()
+		}
+	}()
+	if err != nil {
+		return nil, fmt.Errorf("Error sending call: %s", err)
+	}
+	var r master.Response
+	if err = resp.Decode(&r); err != nil {
+		return nil, fmt.Errorf("Error decoding response: %s", err)
+	}
+	return &r, nil
+}
+
+// sendMasterCommand sends a non streaming call whose response has no body
+func sendMasterCommand(cli calls.Sender, call *master.Call) error {
+	resp, err := cli.Send(context.Background(), calls.NonStreaming(call))
+	defer func() {
+		if resp != nil {
+			resp.Close()
+		}
+	}()
+	if err != nil {
+		return fmt.Errorf("Error sending call: %s", err)
+	}
+	return nil
+}
+
+// findAgents returns the registered agents whose id or hostname starts with name
+func findAgents(cli calls.Sender, name string) ([]master.Response_GetAgents_Agent, error) {
+	r, err := sendMasterCall(cli, calls.GetAgents())
+	if err != nil {
+		return nil, err
+	}
+	agents := []master.Response_GetAgents_Agent{}
+	for _, a := range r.GetGetAgents().GetAgents() {
+		if strings.HasPrefix(a.GetAgentInfo().ID.Value, name) ||
+			strings.HasPrefix(a.GetAgentInfo().Hostname, name) {
+			agents = append(agents, a)
+		}
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("Unable to find agent with id or hostname starting with %s", name)
+	}
+	return agents, nil
+}
+
+// findAgent is like findAgents but fails if name matches more than one agent
+func findAgent(cli calls.Sender, name string) (*master.Response_GetAgents_Agent, error) {
+	agents, err := findAgents(cli, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(agents) > 1 {
+		hostnames := []string{}
+		for _, a := range agents {
+			hostnames = append(hostnames, a.GetAgentInfo().Hostname)
+		}
+		return nil, fmt.Errorf("Agent %s is ambiguous, it matches: %s", name, strings.Join(hostnames, ", "))
+	}
+	return &agents[0], nil
+}
+
 func presetRequiredFlags() {
 	if viper.IsSet("master.url") && viper.GetString("master.url") != "" {
 		masterCmd.PersistentFlags().Set("url", viper.GetString("master.url"))
@@ -65,7 +149,7 @@ type MasterCallDef struct {
 	call  func() *master.Call
 	desc  string
 	json  func(r *master.Response) ([]byte, error)
-	print func(r *master.Response) error
+	print func(r *master.Response, format string) error
 }
 
 type MasterCallsDef map[string]MasterCallDef
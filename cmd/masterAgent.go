@@ -0,0 +1,198 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/spf13/cobra"
+)
+
+type masterAgentOptions struct {
+	maxGracePeriod time.Duration
+	markGone       bool
+	wait           bool
+}
+
+var masterAgentOpts = &masterAgentOptions{}
+
+var terminalTaskStates = map[mesos.TaskState]bool{
+	mesos.TASK_FINISHED:         true,
+	mesos.TASK_FAILED:           true,
+	mesos.TASK_KILLED:           true,
+	mesos.TASK_ERROR:            true,
+	mesos.TASK_LOST:             true,
+	mesos.TASK_DROPPED:          true,
+	mesos.TASK_GONE:             true,
+	mesos.TASK_GONE_BY_OPERATOR: true,
+}
+
+// sendAgentCall resolves the agent given as argument and sends the call built for it
+func sendAgentCall(name string, call func(id mesos.AgentID) *master.Call) (*master.Response_GetAgents_Agent, error) {
+	a, err := findAgent(masterCli, name)
+	if err != nil {
+		return nil, err
+	}
+	if err = sendMasterCommand(masterCli, call(a.GetAgentInfo().ID)); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// waitDrained follows master events and reports tasks leaving the agent until it is drained
+func waitDrained(a *master.Response_GetAgents_Agent) error {
+	agentID := a.GetAgentInfo().ID.Value
+	resp, err := masterCli.Send(context.Background(), calls.NonStreaming(calls.Subscribe()))
+	defer func() {
+		if resp != nil {
+			resp.Close()
+		}
+	}()
+	if err != nil {
+		return fmt.Errorf("Error subscribing to master events: %s", err)
+	}
+	tasks := map[string]bool{}
+	for {
+		var e master.Event
+		if err = resp.Decode(&e); err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("Master closed the event stream before agent was drained")
+			}
+			return fmt.Errorf("Error decoding event: %s", err)
+		}
+		switch e.GetType() {
+		case master.Event_SUBSCRIBED:
+			for _, task := range e.GetSubscribed().GetGetState().GetGetTasks().GetTasks() {
+				if task.GetAgentID().Value == agentID && !terminalTaskStates[task.GetState()] {
+					tasks[task.GetTaskID().Value] = true
+				}
+			}
+			fmt.Printf("%s: %d tasks remaining on %s\n", time.Now().Format(time.RFC3339), len(tasks), a.GetAgentInfo().Hostname)
+		case master.Event_TASK_UPDATED:
+			tu := e.GetTaskUpdated()
+			id := tu.GetStatus().GetTaskID().Value
+			if tasks[id] && terminalTaskStates[tu.GetState()] {
+				delete(tasks, id)
+				fmt.Printf("%s: task %s %s, %d tasks remaining\n", time.Now().Format(time.RFC3339), id, tu.GetState(), len(tasks))
+			}
+		case master.Event_AGENT_REMOVED:
+			if e.GetAgentRemoved().GetAgentID().Value == agentID {
+				fmt.Printf("%s: agent %s removed\n", time.Now().Format(time.RFC3339), a.GetAgentInfo().Hostname)
+				return nil
+			}
+		case master.Event_HEARTBEAT:
+			// Drain state is not part of the events, poll it on each heartbeat
+			current, err := findAgent(masterCli, agentID)
+			if err != nil {
+				return err
+			}
+			if current.GetDrainInfo().GetState() == mesos.DRAINED {
+				fmt.Printf("%s: agent %s drained\n", time.Now().Format(time.RFC3339), a.GetAgentInfo().Hostname)
+				return nil
+			}
+		}
+	}
+}
+
+var masterAgentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Drain, deactivate or reactivate agents",
+	Long:  "Drain, deactivate or reactivate agents (requires Mesos 1.9+)",
+}
+
+var masterAgentDrainCmd = &cobra.Command{
+	Use:     "drain [agent]",
+	Short:   "Drain an agent",
+	Example: "agent drain mesos-agent123 --max-grace-period 10m --wait",
+	Long: `Drain an agent (DRAIN_AGENT): the agent is deactivated and all its tasks are killed.
+Once all tasks are terminal, the agent is DRAINED.
+
+Agent is an agent id or hostname prefix.
+With --wait, tasks leaving the agent are reported until it is drained.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := sendAgentCall(args[0], func(id mesos.AgentID) *master.Call {
+			drain := &master.Call_DrainAgent{
+				AgentID:  id,
+				MarkGone: &masterAgentOpts.markGone,
+			}
+			if masterAgentOpts.maxGracePeriod > 0 {
+				drain.MaxGracePeriod = &mesos.DurationInfo{Nanoseconds: masterAgentOpts.maxGracePeriod.Nanoseconds()}
+			}
+			return &master.Call{
+				Type:       master.Call_DRAIN_AGENT,
+				DrainAgent: drain,
+			}
+		})
+		if err != nil || !masterAgentOpts.wait {
+			return err
+		}
+		return waitDrained(a)
+	},
+}
+
+var masterAgentDeactivateCmd = &cobra.Command{
+	Use:     "deactivate [agent]",
+	Short:   "Deactivate an agent",
+	Example: "agent deactivate mesos-agent123",
+	Long: `Deactivate an agent (DEACTIVATE_AGENT): no more offers are sent for its resources.
+
+Agent is an agent id or hostname prefix.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := sendAgentCall(args[0], func(id mesos.AgentID) *master.Call {
+			return &master.Call{
+				Type:            master.Call_DEACTIVATE_AGENT,
+				DeactivateAgent: &master.Call_DeactivateAgent{AgentID: id},
+			}
+		})
+		return err
+	},
+}
+
+var masterAgentReactivateCmd = &cobra.Command{
+	Use:     "reactivate [agent]",
+	Short:   "Reactivate an agent",
+	Example: "agent reactivate mesos-agent123",
+	Long: `Reactivate a deactivated or drained agent (REACTIVATE_AGENT).
+
+Agent is an agent id or hostname prefix.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := sendAgentCall(args[0], func(id mesos.AgentID) *master.Call {
+			return &master.Call{
+				Type:            master.Call_REACTIVATE_AGENT,
+				ReactivateAgent: &master.Call_ReactivateAgent{AgentID: id},
+			}
+		})
+		return err
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterAgentCmd)
+	masterAgentCmd.AddCommand(masterAgentDrainCmd, masterAgentDeactivateCmd, masterAgentReactivateCmd)
+
+	masterAgentDrainCmd.Flags().DurationVar(&masterAgentOpts.maxGracePeriod, "max-grace-period", 0, "maximum grace period given to tasks to terminate (default to tasks kill policy)")
+	masterAgentDrainCmd.Flags().BoolVar(&masterAgentOpts.markGone, "mark-gone", false, "mark the agent gone once drained (irreversible)")
+	masterAgentDrainCmd.Flags().BoolVarP(&masterAgentOpts.wait, "wait", "w", false, "wait for the agent to be drained, reporting tasks leaving the agent")
+}
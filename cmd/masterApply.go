@@ -0,0 +1,258 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/mesos/mesos-go/api/v1/lib/quota"
+	"github.com/spf13/cobra"
+)
+
+type masterApplyOptions struct {
+	file   string
+	dryRun bool
+	prune  bool
+}
+
+var masterApplyOpts = &masterApplyOptions{}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// planQuotas prints the quota changes and returns the UPDATE_QUOTA call to apply them, if any
+func planQuotas(desired map[string]quotaPolicy) (*master.Call, error) {
+	current, err := currentQuotas()
+	if err != nil {
+		return nil, err
+	}
+	roles := map[string]bool{}
+	for role := range desired {
+		roles[role] = true
+	}
+	if masterApplyOpts.prune {
+		for role := range current {
+			roles[role] = true
+		}
+	}
+	configs := []quota.QuotaConfig{}
+	fmt.Println("Quotas:")
+	for _, role := range sortedKeys(roles) {
+		d, inDesired := desired[role]
+		c, inCurrent := current[role]
+		switch {
+		case inDesired && !inCurrent:
+			fmt.Printf("+ %s: %s\n", role, d)
+		case !inDesired && inCurrent:
+			fmt.Printf("- %s: %s\n", role, c)
+		case !d.equal(c):
+			fmt.Printf("~ %s: %s => %s\n", role, c, d)
+		default:
+			fmt.Printf("  %s: %s\n", role, d)
+			continue
+		}
+		// a config without guarantees nor limits removes the quota
+		config := quota.QuotaConfig{
+			Role:       role,
+			Guarantees: map[string]mesos.Value_Scalar{},
+			Limits:     map[string]mesos.Value_Scalar{},
+		}
+		for name, value := range d.Guarantees {
+			config.Guarantees[name] = mesos.Value_Scalar{Value: value}
+		}
+		for name, value := range d.Limits {
+			config.Limits[name] = mesos.Value_Scalar{Value: value}
+		}
+		configs = append(configs, config)
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	force := false
+	return &master.Call{
+		Type: master.Call_UPDATE_QUOTA,
+		UpdateQuota: &master.Call_UpdateQuota{
+			Force:        &force,
+			QuotaConfigs: configs,
+		},
+	}, nil
+}
+
+// planWeights prints the weight changes and returns the UPDATE_WEIGHTS call to apply them, if any
+func planWeights(desired map[string]float64) (*master.Call, error) {
+	current, err := currentWeights()
+	if err != nil {
+		return nil, err
+	}
+	roles := map[string]bool{}
+	for role := range desired {
+		roles[role] = true
+	}
+	if masterApplyOpts.prune {
+		for role := range current {
+			roles[role] = true
+		}
+	}
+	weightInfos := []mesos.WeightInfo{}
+	fmt.Println("Weights:")
+	for _, role := range sortedKeys(roles) {
+		d, inDesired := desired[role]
+		c, inCurrent := current[role]
+		switch {
+		case inDesired && !inCurrent:
+			fmt.Printf("+ %s: %.1f\n", role, d)
+		case !inDesired && inCurrent:
+			// weights cannot be removed, they are reset to the default weight
+			d = 1.0
+			fmt.Printf("- %s: %.1f\n", role, c)
+		case d != c:
+			fmt.Printf("~ %s: %.1f => %.1f\n", role, c, d)
+		default:
+			fmt.Printf("  %s: %.1f\n", role, d)
+			continue
+		}
+		r := role
+		weightInfos = append(weightInfos, mesos.WeightInfo{Role: &r, Weight: d})
+	}
+	if len(weightInfos) == 0 {
+		return nil, nil
+	}
+	return calls.UpdateWeights(weightInfos...), nil
+}
+
+// planMaintenance prints the maintenance schedule changes and returns the
+// UPDATE_MAINTENANCE_SCHEDULE call to apply them, if any
+func planMaintenance(desired *maintenancePolicy) (*master.Call, error) {
+	current, err := maintenanceSchedule()
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := desired.schedule()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Maintenance windows:")
+	if !printScheduleDiff(current, schedule) {
+		return nil, nil
+	}
+	return calls.UpdateMaintenanceSchedule(schedule), nil
+}
+
+var masterApplyCmd = &cobra.Command{
+	Use:     "apply",
+	Short:   "Apply a cluster policy file",
+	Example: "apply -f policy.yaml --dry-run",
+	Long: `Apply quotas, weights and maintenance windows from a YAML or JSON policy file.
+
+The current configuration is fetched from the master, the changes are printed and only the
+needed UPDATE_QUOTA, UPDATE_WEIGHTS and UPDATE_MAINTENANCE_SCHEDULE calls are sent.
+Sections missing from the file are left untouched. Roles missing from the file keep their
+quota and weight, unless --prune is set.
+
+Example of policy file (see also export):
+
+  quotas:
+    dev:
+      guarantees: {cpus: 4, mem: 8192}
+      limits: {cpus: 8, mem: 16384}
+  weights:
+    dev: 2
+  maintenance:
+    windows:
+    - machines: [mesos-agent123/10.0.0.1]
+      start: 2026-11-01T02:00:00Z
+      duration: 4h`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if masterApplyOpts.file == "" {
+			return fmt.Errorf("Missing policy file in -f")
+		}
+		policy, err := readPolicy(masterApplyOpts.file)
+		if err != nil {
+			return err
+		}
+
+		plan := []*master.Call{}
+		if policy.Quotas != nil {
+			call, err := planQuotas(policy.Quotas)
+			if err != nil {
+				return err
+			}
+			if call != nil {
+				supported, err := masterVersionAtLeast(1, 9)
+				if err != nil {
+					return err
+				}
+				if !supported {
+					return fmt.Errorf("Master is older than 1.9 and does not support UPDATE_QUOTA, quotas cannot be applied")
+				}
+				plan = append(plan, call)
+			}
+		}
+		if policy.Weights != nil {
+			call, err := planWeights(policy.Weights)
+			if err != nil {
+				return err
+			}
+			if call != nil {
+				plan = append(plan, call)
+			}
+		}
+		if policy.Maintenance != nil {
+			call, err := planMaintenance(policy.Maintenance)
+			if err != nil {
+				return err
+			}
+			if call != nil {
+				plan = append(plan, call)
+			}
+		}
+
+		if len(plan) == 0 {
+			fmt.Println("\nNothing to apply")
+			return nil
+		}
+		if masterApplyOpts.dryRun {
+			return nil
+		}
+		for _, call := range plan {
+			if verbose {
+				fmt.Printf("Sending %s\n", call.GetType())
+			}
+			if err := sendMasterCommand(masterCli, call); err != nil {
+				return fmt.Errorf("Error applying %s: %s", call.GetType(), err)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterApplyCmd)
+	masterApplyCmd.Flags().StringVarP(&masterApplyOpts.file, "file", "f", "", "YAML or JSON policy file")
+	masterApplyCmd.Flags().BoolVar(&masterApplyOpts.dryRun, "dry-run", false, "only show the changes")
+	masterApplyCmd.Flags().BoolVar(&masterApplyOpts.prune, "prune", false, "remove quotas and reset weights of roles missing from the file")
+}
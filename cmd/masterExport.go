@@ -0,0 +1,98 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type masterExportOptions struct {
+	what   []string
+	output string
+}
+
+var masterExportOpts = &masterExportOptions{}
+
+var masterExportCmd = &cobra.Command{
+	Use:     "export",
+	Short:   "Export the cluster policy to a file",
+	Example: "export --what quota,weights -o policy.yaml",
+	Long: `Export quotas, weights and maintenance windows to a YAML or JSON policy file,
+which can be edited and applied to the same or another cluster (see apply).
+
+The file is written as JSON if its name ends with .json, as YAML otherwise.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy := clusterPolicy{}
+		for _, what := range masterExportOpts.what {
+			switch what {
+			case "quota":
+				quotas, err := currentQuotas()
+				if err != nil {
+					return err
+				}
+				policy.Quotas = quotas
+			case "weights":
+				weights, err := currentWeights()
+				if err != nil {
+					return err
+				}
+				policy.Weights = weights
+			case "maintenance":
+				schedule, err := maintenanceSchedule()
+				if err != nil {
+					return err
+				}
+				policy.Maintenance = schedulePolicy(schedule)
+			default:
+				return fmt.Errorf("Unknown --what value %s, expected quota, weights or maintenance", what)
+			}
+		}
+
+		var bytes []byte
+		var err error
+		if strings.HasSuffix(masterExportOpts.output, ".json") {
+			bytes, err = json.MarshalIndent(policy.exported(), "", "  ")
+			bytes = append(bytes, '\n')
+		} else {
+			bytes, err = yaml.Marshal(policy.exported())
+		}
+		if err != nil {
+			return fmt.Errorf("Error marshalling policy: %s", err)
+		}
+		if masterExportOpts.output == "" || masterExportOpts.output == "-" {
+			_, err = os.Stdout.Write(bytes)
+			return err
+		}
+		if err = ioutil.WriteFile(masterExportOpts.output, bytes, 0644); err != nil {
+			return fmt.Errorf("Error writing file %s: %s", masterExportOpts.output, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterExportCmd)
+	masterExportCmd.Flags().StringSliceVar(&masterExportOpts.what, "what", []string{"quota", "weights", "maintenance"}, "configuration to export: quota, weights and/or maintenance")
+	masterExportCmd.Flags().StringVarP(&masterExportOpts.output, "output", "o", "-", "output file (- for stdout)")
+}
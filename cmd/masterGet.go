@@ -19,7 +19,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"time"
@@ -36,6 +35,7 @@ import (
 type masterGetOptions struct {
 	timeout time.Duration
 	json    bool
+	output  string
 }
 
 var masterGetOpts = masterGetOptions{}
@@ -47,8 +47,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetHealth(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			fmt.Println(r.GetGetHealth().GetHealthy())
+		print: func(r *master.Response, format string) error {
+			printRecord(format, []string{"healthy"}, []string{fmt.Sprintf("%v", r.GetGetHealth().GetHealthy())})
 			return nil
 		},
 	},
@@ -58,8 +58,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetLoggingLevel(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			fmt.Println(r.GetGetLoggingLevel().GetLevel())
+		print: func(r *master.Response, format string) error {
+			printRecord(format, []string{"level"}, []string{fmt.Sprintf("%d", r.GetGetLoggingLevel().GetLevel())})
 			return nil
 		},
 	},
@@ -69,8 +69,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetMaintenanceSchedule(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"agents", "start", "duration"})
 			for _, w := range r.GetMaintenanceSchedule.Schedule.Windows {
 				agents := []string{}
@@ -94,8 +94,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetMaintenanceStatus(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"agent", "status", "frameworks"})
 			for _, d := range r.GetMaintenanceStatus.Status.DrainingMachines {
 				frameworks := []string{}
@@ -114,6 +114,7 @@ var masterGetCalls = MasterCallsDef{
 				table.Append([]string{
 					fmt.Sprintf("%s (%s)", d.GetHostname(), d.GetIP()),
 					"down",
+					"",
 				})
 			}
 			table.SetBorder(false)
@@ -129,18 +130,16 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetMaster(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
-			table.Append([]string{"id:", r.GetGetMaster().GetMasterInfo().GetID()})
-			table.Append([]string{"hostname:", r.GetGetMaster().GetMasterInfo().GetHostname()})
-			table.Append([]string{"ip:", r.GetGetMaster().GetMasterInfo().GetAddress().GetIP()})
-			table.Append([]string{"port:", fmt.Sprintf("%d", r.GetGetMaster().GetMasterInfo().GetAddress().GetPort())})
-			table.Append([]string{"version:", r.GetGetMaster().GetMasterInfo().GetVersion()})
-			table.SetBorder(false)
-			table.SetHeaderLine(false)
-			table.SetColumnSeparator("")
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.Render()
+		print: func(r *master.Response, format string) error {
+			printRecord(format,
+				[]string{"id", "hostname", "ip", "port", "version"},
+				[]string{
+					r.GetGetMaster().GetMasterInfo().GetID(),
+					r.GetGetMaster().GetMasterInfo().GetHostname(),
+					r.GetGetMaster().GetMasterInfo().GetAddress().GetIP(),
+					fmt.Sprintf("%d", r.GetGetMaster().GetMasterInfo().GetAddress().GetPort()),
+					r.GetGetMaster().GetMasterInfo().GetVersion(),
+				})
 			return nil
 		},
 	},
@@ -158,8 +157,9 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetMetrics(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
+			table.SetHeader([]string{"name", "value"})
 			for _, m := range r.GetGetMetrics().GetMetrics() {
 				table.Append([]string{m.GetName(), fmt.Sprintf("%f", m.GetValue())})
 			}
@@ -176,8 +176,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetOperations(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"agent", "framework", "type", "status"})
 			for _, o := range r.GetGetOperations().GetOperations() {
 				table.Append([]string{o.GetAgentID().GetValue(), o.GetFrameworkID().GetValue(), o.GetInfo().Type.String(), o.GetLatestStatus().State.String()})
@@ -195,8 +195,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetQuota(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			quotas := map[string]map[string][]float64{}
 			resourcesMap := map[string]bool{}
 			if len(r.GetGetQuota().GetStatus().Configs) > 0 {
@@ -215,7 +215,7 @@ var masterGetCalls = MasterCallsDef{
 							quotas[r][name] = make([]float64, 2)
 							resourcesMap[name] = true
 						}
-						quotas[r][name][1] = c.GetGuarantees()[name].Value
+						quotas[r][name][1] = c.GetLimits()[name].Value
 					}
 				}
 			} else {
@@ -267,8 +267,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetRoles(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			resourcesMap := map[string]bool{}
 			roleResources := map[string]map[string]string{}
 			for _, role := range r.GetGetRoles().GetRoles() {
@@ -303,7 +303,7 @@ var masterGetCalls = MasterCallsDef{
 				resources = append(resources, n)
 			}
 			sort.Strings(resources)
-			header := []string{"role"}
+			header := []string{"role", "weight"}
 			for _, n := range resources {
 				header = append(header, n)
 			}
@@ -341,8 +341,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetTasks(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"agent", "framework", "task_id", "type", "state"})
 			for _, task := range r.GetGetTasks().GetPendingTasks() {
 				table.Append([]string{task.GetAgentID().Value, task.GetFrameworkID().Value, task.GetTaskID().Value, "pending", task.GetState().String()})
@@ -370,20 +370,18 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetVersion(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
-			table.Append([]string{"version:", r.GetGetVersion().VersionInfo.GetVersion()})
-			table.Append([]string{"build_date:", r.GetGetVersion().VersionInfo.GetBuildDate()})
-			table.Append([]string{"build_time:", fmt.Sprintf("%v", int64(r.GetGetVersion().VersionInfo.GetBuildTime()))})
-			table.Append([]string{"build_user:", r.GetGetVersion().VersionInfo.GetBuildUser()})
-			table.Append([]string{"git_branch:", r.GetGetVersion().VersionInfo.GetGitBranch()})
-			table.Append([]string{"git_sha:", r.GetGetVersion().VersionInfo.GetGitSHA()})
-			table.Append([]string{"git_tag:", r.GetGetVersion().VersionInfo.GetGitTag()})
-			table.SetBorder(false)
-			table.SetHeaderLine(false)
-			table.SetColumnSeparator("")
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.Render()
+		print: func(r *master.Response, format string) error {
+			printRecord(format,
+				[]string{"version", "build_date", "build_time", "build_user", "git_branch", "git_sha", "git_tag"},
+				[]string{
+					r.GetGetVersion().VersionInfo.GetVersion(),
+					r.GetGetVersion().VersionInfo.GetBuildDate(),
+					fmt.Sprintf("%v", int64(r.GetGetVersion().VersionInfo.GetBuildTime())),
+					r.GetGetVersion().VersionInfo.GetBuildUser(),
+					r.GetGetVersion().VersionInfo.GetGitBranch(),
+					r.GetGetVersion().VersionInfo.GetGitSHA(),
+					r.GetGetVersion().VersionInfo.GetGitTag(),
+				})
 			return nil
 		},
 	},
@@ -393,8 +391,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetWeights(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"role", "weight"})
 			for _, w := range r.GetGetWeights().GetWeightInfos() {
 				table.Append([]string{w.GetRole(), fmt.Sprintf("%.1f", w.GetWeight())})
@@ -413,8 +411,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetAgents(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"id", "hostname", "version", "registered"})
 			for _, a := range r.GetGetAgents().GetAgents() {
 				table.Append([]string{
@@ -441,8 +439,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetExecutors(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"agent", "framework", "id", "name"})
 			for _, e := range r.GetGetExecutors().GetExecutors() {
 				ei := e.GetExecutorInfo()
@@ -467,8 +465,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetFlags(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"name", "value"})
 			for _, f := range r.GetGetFlags().GetFlags() {
 				table.Append([]string{f.GetName(), f.GetValue()})
@@ -487,8 +485,8 @@ var masterGetCalls = MasterCallsDef{
 		json: func(r *master.Response) ([]byte, error) {
 			return json.MarshalIndent(r.GetGetFrameworks(), "", "  ")
 		},
-		print: func(r *master.Response) error {
-			table := tablewriter.NewWriter(os.Stdout)
+		print: func(r *master.Response, format string) error {
+			table := newTable(format)
 			table.SetHeader([]string{"id", "name", "roles", "principal", "active", "connected", "recovered"})
 			for _, f := range r.GetGetFrameworks().GetFrameworks() {
 				fi := f.GetFrameworkInfo()
@@ -540,6 +538,13 @@ var masterGetCmd = &cobra.Command{
 	Args:  masterGetCalls.validateArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key := strings.Join(args, " ")
+		format, tmpl, err := parseOutputFlags(masterGetOpts.output, masterGetOpts.json)
+		if err != nil {
+			return err
+		}
+		if key == "state" && isCSVOutput(format) {
+			return fmt.Errorf("Output format %s is not supported by get state, which prints several tables", format)
+		}
 		resp, err := masterCli.Send(context.Background(), calls.NonStreaming(masterGetCalls[key].call()))
 		defer func() {
 			if resp != nil {
@@ -554,22 +559,23 @@ var masterGetCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("Error decoding response: %s", err)
 		}
-		if masterGetOpts.json || masterGetCalls[key].print == nil {
-			decode := masterGetCalls[key].json
-			if decode == nil {
-				decode = func(r *master.Response) ([]byte, error) {
-					return json.MarshalIndent(r, "", "  ")
-				}
-			}
-			if j, err := decode(&e); err == nil {
-				fmt.Println(string(j))
-			} else {
-				return fmt.Errorf("Error marshalling response as JSON: %s", err)
+		if isTableOutput(format) && masterGetCalls[key].print != nil {
+			return masterGetCalls[key].print(&e, format)
+		}
+		decode := masterGetCalls[key].json
+		if decode == nil {
+			decode = func(r *master.Response) ([]byte, error) {
+				return json.MarshalIndent(r, "", "  ")
 			}
-		} else {
-			masterGetCalls[key].print(&e)
 		}
-		return nil
+		j, err := decode(&e)
+		if err != nil {
+			return fmt.Errorf("Error marshalling response as JSON: %s", err)
+		}
+		if isTableOutput(format) {
+			format = "json"
+		}
+		return printStructured(j, format, tmpl)
 	},
 }
 
@@ -577,10 +583,11 @@ func init() {
 	masterCmd.AddCommand(masterGetCmd)
 	masterGetCmd.Flags().DurationVar(&masterGetOpts.timeout, "timeout", 0, "timeout duration (used for metrics call see --help)")
 	masterGetCmd.Flags().BoolVarP(&masterGetOpts.json, "json", "j", false, "json output")
+	addOutputFlag(masterGetCmd, &masterGetOpts.output)
 
 	// GetState calls other actions
 	stateCall := masterGetCalls["state"]
-	stateCall.print = func(r *master.Response) error {
+	stateCall.print = func(r *master.Response, format string) error {
 		fr := master.Response{
 			GetAgents:     r.GetGetState().GetGetAgents(),
 			GetFrameworks: r.GetGetState().GetGetFrameworks(),
@@ -589,7 +596,7 @@ func init() {
 		}
 		for _, call := range []string{"agents", "frameworks", "executors", "tasks"} {
 			fmt.Printf("\nState of %s:\n", call)
-			if err := masterGetCalls[call].print(&fr); err != nil {
+			if err := masterGetCalls[call].print(&fr, format); err != nil {
 				return err
 			}
 		}
@@ -16,10 +16,7 @@ limitations under the License.
 package cmd
 
 import (
-	"context"
-	"fmt"
-	"strings"
-
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
 	"github.com/mesos/mesos-go/api/v1/lib/master"
 	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
 	"github.com/spf13/cobra"
@@ -27,6 +24,7 @@ import (
 
 type masterListOptions struct {
 	path string
+	listFilesOptions
 }
 
 var masterListOpts = &masterListOptions{}
@@ -47,26 +45,19 @@ var masterListCmd = &cobra.Command{
 	Long:  masterListCalls.describeCalls(),
 	Args:  masterListCalls.validateArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		key := strings.Join(args, " ")
-		resp, err := masterCli.Send(context.Background(), calls.NonStreaming(masterListCalls[key].call()))
-		defer func() {
-			if resp != nil {
-				resp.Close()
+		list := func(path string) ([]mesos.FileInfo, error) {
+			r, err := sendMasterCall(masterCli, calls.ListFiles(path))
+			if err != nil {
+				return nil, err
 			}
-		}()
-		if err != nil {
-			return fmt.Errorf("Error sending call: %s", err)
-		}
-		var e master.Response
-		err = resp.Decode(&e)
-		if err != nil {
-			return fmt.Errorf("Error decoding response: %s", err)
+			return r.GetListFiles().GetFileInfos(), nil
 		}
-		return nil
+		return listFiles(list, masterListOpts.path, masterListOpts.listFilesOptions)
 	},
 }
 
 func init() {
 	masterCmd.AddCommand(masterListCmd)
 	masterListCmd.Flags().StringVar(&masterListOpts.path, "path", "", "path to list files")
+	addListFilesFlags(masterListCmd, &masterListOpts.listFilesOptions)
 }
@@ -0,0 +1,369 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/maintenance"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/spf13/cobra"
+)
+
+type masterMaintenanceOptions struct {
+	machines []string
+	hosts    []string
+	start    string
+	duration time.Duration
+	dryRun   bool
+	yes      bool
+}
+
+var masterMaintenanceOpts = &masterMaintenanceOptions{}
+
+// maintenanceSchedule returns the current maintenance schedule (GET_MAINTENANCE_SCHEDULE)
+func maintenanceSchedule() (maintenance.Schedule, error) {
+	r, err := sendMasterCall(masterCli, calls.GetMaintenanceSchedule())
+	if err != nil {
+		return maintenance.Schedule{}, err
+	}
+	return r.GetGetMaintenanceSchedule().GetSchedule(), nil
+}
+
+// matchMachine returns true if the machine matches the host[/ip] specification
+func matchMachine(spec string, m mesos.MachineID) bool {
+	hi := strings.SplitN(spec, "/", 2)
+	if hi[0] != "" && hi[0] != m.GetHostname() {
+		return false
+	}
+	if len(hi) == 2 && hi[1] != m.GetIP() {
+		return false
+	}
+	return true
+}
+
+func containsMachine(machines []mesos.MachineID, m mesos.MachineID) bool {
+	for _, c := range machines {
+		if c.GetHostname() == m.GetHostname() && c.GetIP() == m.GetIP() {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduledMachines returns the machines of the schedule matching the host[/ip] specifications,
+// failing if any specification does not match exactly one scheduled machine
+func scheduledMachines(schedule maintenance.Schedule, specs []string) ([]mesos.MachineID, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("Missing --machine")
+	}
+	machines := []mesos.MachineID{}
+	for _, spec := range specs {
+		matches := []mesos.MachineID{}
+		for _, w := range schedule.Windows {
+			for _, m := range w.MachineIDs {
+				if matchMachine(spec, m) && !containsMachine(matches, m) {
+					matches = append(matches, m)
+				}
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("Machine %s is not in the maintenance schedule", spec)
+		case 1:
+			machines = append(machines, matches[0])
+		default:
+			return nil, fmt.Errorf("Machine %s is ambiguous, specify it as host/ip", spec)
+		}
+	}
+	return machines, nil
+}
+
+func sendMaintenanceCall(call func(...mesos.MachineID) *master.Call) error {
+	schedule, err := maintenanceSchedule()
+	if err != nil {
+		return err
+	}
+	machines, err := scheduledMachines(schedule, masterMaintenanceOpts.machines)
+	if err != nil {
+		return err
+	}
+	return sendMasterCommand(masterCli, call(machines...))
+}
+
+// parseMachine parses a host[/ip] machine specification
+func parseMachine(spec string) (mesos.MachineID, error) {
+	hi := strings.SplitN(spec, "/", 2)
+	m := mesos.MachineID{}
+	if hi[0] != "" {
+		m.Hostname = &hi[0]
+	}
+	if len(hi) == 2 && hi[1] != "" {
+		m.IP = &hi[1]
+	}
+	if m.Hostname == nil && m.IP == nil {
+		return m, fmt.Errorf("Bad machine format for %s expecting host[/ip]", spec)
+	}
+	return m, nil
+}
+
+// parseTime parses a RFC3339 time, seconds and timezone being optional (UTC by default)
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Bad time format for %s expecting RFC3339 (example: 2026-11-01T02:00Z)", s)
+}
+
+// formatWindow formats a maintenance window with its machines sorted, so that the same
+// windows are formatted the same way whatever the order of their machines
+func formatWindow(w maintenance.Window) string {
+	machines := []string{}
+	for _, m := range w.MachineIDs {
+		machines = append(machines, fmt.Sprintf("%s (%s)", m.GetHostname(), m.GetIP()))
+	}
+	sort.Strings(machines)
+	start := time.Unix(0, w.Unavailability.Start.GetNanoseconds()).UTC()
+	duration := time.Duration(w.Unavailability.Duration.GetNanoseconds())
+	return fmt.Sprintf("%s %s: %s", start.Format(time.RFC3339), duration, strings.Join(machines, ", "))
+}
+
+// printScheduleDiff prints the windows removed and added between two schedules,
+// returning false if they are the same
+func printScheduleDiff(current, desired maintenance.Schedule) bool {
+	currentWindows := map[string]bool{}
+	for _, w := range current.Windows {
+		currentWindows[formatWindow(w)] = true
+	}
+	desiredWindows := map[string]bool{}
+	for _, w := range desired.Windows {
+		desiredWindows[formatWindow(w)] = true
+	}
+	changed := false
+	for _, w := range current.Windows {
+		if desiredWindows[formatWindow(w)] {
+			fmt.Printf("  %s\n", formatWindow(w))
+		} else {
+			fmt.Printf("- %s\n", formatWindow(w))
+			changed = true
+		}
+	}
+	for _, w := range desired.Windows {
+		if !currentWindows[formatWindow(w)] {
+			fmt.Printf("+ %s\n", formatWindow(w))
+			changed = true
+		}
+	}
+	return changed
+}
+
+// updateSchedule applies the change to the current maintenance schedule, prints the difference
+// and posts the resulting schedule (UPDATE_MAINTENANCE_SCHEDULE)
+func updateSchedule(change func(schedule maintenance.Schedule) (maintenance.Schedule, error)) error {
+	current, err := maintenanceSchedule()
+	if err != nil {
+		return err
+	}
+	desired, err := change(current)
+	if err != nil {
+		return err
+	}
+	if !printScheduleDiff(current, desired) {
+		fmt.Println("Maintenance schedule is unchanged")
+		return nil
+	}
+	if masterMaintenanceOpts.dryRun {
+		return nil
+	}
+	return sendMasterCommand(masterCli, calls.UpdateMaintenanceSchedule(desired))
+}
+
+var masterMaintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Manage machines maintenance",
+	Long: `Manage machines maintenance.
+
+Machines are specified as host[/ip] and must be part of the maintenance schedule
+(see get maintenance schedule).`,
+}
+
+var masterMaintenanceStartCmd = &cobra.Command{
+	Use:     "start",
+	Short:   "Start maintenance of machines",
+	Example: "maintenance start --machine mesos-agent123/10.0.0.1",
+	Long:    "Start maintenance of scheduled machines (START_MAINTENANCE): agents on these machines are brought down.",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendMaintenanceCall(calls.StartMaintenance)
+	},
+}
+
+var masterMaintenanceStopCmd = &cobra.Command{
+	Use:     "stop",
+	Short:   "Stop maintenance of machines",
+	Example: "maintenance stop --machine mesos-agent123/10.0.0.1",
+	Long:    "Stop maintenance of machines (STOP_MAINTENANCE): machines are brought up and removed from the schedule.",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendMaintenanceCall(calls.StopMaintenance)
+	},
+}
+
+var masterMaintenanceAddWindowCmd = &cobra.Command{
+	Use:     "add-window",
+	Short:   "Add a window to the maintenance schedule",
+	Example: "maintenance add-window --hosts mesos-agent123,mesos-agent124/10.0.0.2 --start 2026-11-01T02:00Z --duration 4h",
+	Long: `Add a window to the maintenance schedule, keeping existing windows.
+
+Machines can only be part of one window.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(masterMaintenanceOpts.hosts) == 0 {
+			return fmt.Errorf("Missing --hosts")
+		}
+		if masterMaintenanceOpts.start == "" {
+			return fmt.Errorf("Missing --start")
+		}
+		if masterMaintenanceOpts.duration <= 0 {
+			return fmt.Errorf("Missing --duration")
+		}
+		start, err := parseTime(masterMaintenanceOpts.start)
+		if err != nil {
+			return err
+		}
+		window := maintenance.Window{
+			Unavailability: mesos.Unavailability{
+				Start:    mesos.TimeInfo{Nanoseconds: start.UnixNano()},
+				Duration: &mesos.DurationInfo{Nanoseconds: masterMaintenanceOpts.duration.Nanoseconds()},
+			},
+		}
+		for _, h := range masterMaintenanceOpts.hosts {
+			m, err := parseMachine(h)
+			if err != nil {
+				return err
+			}
+			window.MachineIDs = append(window.MachineIDs, m)
+		}
+		return updateSchedule(func(schedule maintenance.Schedule) (maintenance.Schedule, error) {
+			for _, w := range schedule.Windows {
+				for _, m := range w.MachineIDs {
+					for _, h := range masterMaintenanceOpts.hosts {
+						if matchMachine(h, m) {
+							return schedule, fmt.Errorf("Machine %s is already scheduled in window %s", h, formatWindow(w))
+						}
+					}
+				}
+			}
+			schedule.Windows = append(append([]maintenance.Window{}, schedule.Windows...), window)
+			return schedule, nil
+		})
+	},
+}
+
+var masterMaintenanceRemoveWindowCmd = &cobra.Command{
+	Use:     "remove-window",
+	Short:   "Remove machines or windows from the maintenance schedule",
+	Example: "maintenance remove-window --hosts mesos-agent123 --start 2026-11-01T02:00Z",
+	Long: `Remove machines from the maintenance schedule windows (all of them or the one starting at --start).
+Without --hosts, the whole window starting at --start is removed.
+Windows left without machines are removed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(masterMaintenanceOpts.hosts) == 0 && masterMaintenanceOpts.start == "" {
+			return fmt.Errorf("Missing --hosts or --start")
+		}
+		var start *time.Time
+		if masterMaintenanceOpts.start != "" {
+			t, err := parseTime(masterMaintenanceOpts.start)
+			if err != nil {
+				return err
+			}
+			start = &t
+		}
+		return updateSchedule(func(schedule maintenance.Schedule) (maintenance.Schedule, error) {
+			removed := false
+			windows := []maintenance.Window{}
+			for _, w := range schedule.Windows {
+				if start != nil && w.Unavailability.Start.GetNanoseconds() != start.UnixNano() {
+					windows = append(windows, w)
+					continue
+				}
+				machines := []mesos.MachineID{}
+				for _, m := range w.MachineIDs {
+					remove := len(masterMaintenanceOpts.hosts) == 0
+					for _, h := range masterMaintenanceOpts.hosts {
+						remove = remove || matchMachine(h, m)
+					}
+					if remove {
+						removed = true
+					} else {
+						machines = append(machines, m)
+					}
+				}
+				if len(machines) > 0 {
+					w.MachineIDs = machines
+					windows = append(windows, w)
+				}
+			}
+			if !removed {
+				return schedule, fmt.Errorf("No matching machine in the maintenance schedule")
+			}
+			schedule.Windows = windows
+			return schedule, nil
+		})
+	},
+}
+
+var masterMaintenanceClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all windows from the maintenance schedule",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateSchedule(func(schedule maintenance.Schedule) (maintenance.Schedule, error) {
+			if len(schedule.Windows) > 0 && !masterMaintenanceOpts.dryRun && !masterMaintenanceOpts.yes &&
+				!confirm(fmt.Sprintf("Remove all %d maintenance windows?", len(schedule.Windows))) {
+				return schedule, fmt.Errorf("Aborted")
+			}
+			return maintenance.Schedule{}, nil
+		})
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterMaintenanceCmd)
+	masterMaintenanceCmd.AddCommand(masterMaintenanceStartCmd, masterMaintenanceStopCmd)
+	masterMaintenanceCmd.AddCommand(masterMaintenanceAddWindowCmd, masterMaintenanceRemoveWindowCmd, masterMaintenanceClearCmd)
+
+	for _, c := range []*cobra.Command{masterMaintenanceStartCmd, masterMaintenanceStopCmd} {
+		c.Flags().StringSliceVar(&masterMaintenanceOpts.machines, "machine", []string{}, "machine as host[/ip], can be repeated")
+	}
+	for _, c := range []*cobra.Command{masterMaintenanceAddWindowCmd, masterMaintenanceRemoveWindowCmd, masterMaintenanceClearCmd} {
+		c.Flags().BoolVar(&masterMaintenanceOpts.dryRun, "dry-run", false, "only show the changes to the schedule")
+	}
+	for _, c := range []*cobra.Command{masterMaintenanceAddWindowCmd, masterMaintenanceRemoveWindowCmd} {
+		c.Flags().StringSliceVar(&masterMaintenanceOpts.hosts, "hosts", []string{}, "machines as host[/ip][,host[/ip]...]")
+		c.Flags().StringVar(&masterMaintenanceOpts.start, "start", "", "window start time, RFC3339 (example: 2026-11-01T02:00Z)")
+	}
+	masterMaintenanceAddWindowCmd.Flags().DurationVar(&masterMaintenanceOpts.duration, "duration", 0, "window duration (example: 4h)")
+	masterMaintenanceClearCmd.Flags().BoolVarP(&masterMaintenanceOpts.yes, "yes", "y", false, "do not ask for confirmation")
+}
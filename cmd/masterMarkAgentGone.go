@@ -0,0 +1,136 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/spf13/cobra"
+)
+
+type masterMarkAgentGoneOptions struct {
+	yes   bool
+	force bool
+}
+
+var masterMarkAgentGoneOpts = &masterMarkAgentGoneOptions{}
+
+// masterState returns the overall cluster state (GET_STATE)
+func masterState() (*master.Response_GetState, error) {
+	r, err := sendMasterCall(masterCli, calls.GetState())
+	if err != nil {
+		return nil, err
+	}
+	return r.GetGetState(), nil
+}
+
+// printAgentState prints the tasks and executors the master attributes to the agent
+func printAgentState(state *master.Response_GetState, agentID string) (int, int, error) {
+	tasks := &master.Response_GetTasks{}
+	for _, t := range state.GetGetTasks().GetTasks() {
+		if t.GetAgentID().Value == agentID {
+			tasks.Tasks = append(tasks.Tasks, t)
+		}
+	}
+	for _, t := range state.GetGetTasks().GetUnreachableTasks() {
+		if t.GetAgentID().Value == agentID {
+			tasks.UnreachableTasks = append(tasks.UnreachableTasks, t)
+		}
+	}
+	executors := &master.Response_GetExecutors{}
+	for _, e := range state.GetGetExecutors().GetExecutors() {
+		if e.GetAgentID().Value == agentID {
+			executors.Executors = append(executors.Executors, e)
+		}
+	}
+	fmt.Printf("\nTasks on agent:\n")
+	if err := masterGetCalls["tasks"].print(&master.Response{GetTasks: tasks}, "table"); err != nil {
+		return 0, 0, err
+	}
+	fmt.Printf("\nExecutors on agent:\n")
+	if err := masterGetCalls["executors"].print(&master.Response{GetExecutors: executors}, "table"); err != nil {
+		return 0, 0, err
+	}
+	return len(tasks.Tasks) + len(tasks.UnreachableTasks), len(executors.Executors), nil
+}
+
+var masterMarkAgentGoneCmd = &cobra.Command{
+	Use:     "mark-agent-gone [agent]",
+	Short:   "Mark an agent as gone",
+	Example: "mark-agent-gone mesos-agent123",
+	Long: `Mark an agent as gone (MARK_AGENT_GONE): its tasks are marked TASK_GONE_BY_OPERATOR
+and the agent is not allowed to register again. This is irreversible.
+
+Agent is an agent id or hostname prefix, registered or recovered.
+Active agents are refused unless --force is set.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := masterState()
+		if err != nil {
+			return err
+		}
+		var agentInfo *mesos.AgentInfo
+		active := false
+		matches := []string{}
+		for _, a := range state.GetGetAgents().GetAgents() {
+			ai := a.GetAgentInfo()
+			if strings.HasPrefix(ai.ID.Value, args[0]) || strings.HasPrefix(ai.Hostname, args[0]) {
+				agentInfo = &ai
+				active = a.GetActive()
+				matches = append(matches, ai.Hostname)
+			}
+		}
+		for _, a := range state.GetGetAgents().GetRecoveredAgents() {
+			ai := a
+			if strings.HasPrefix(ai.ID.Value, args[0]) || strings.HasPrefix(ai.Hostname, args[0]) {
+				agentInfo = &ai
+				active = false
+				matches = append(matches, ai.Hostname)
+			}
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("Unable to find agent with id or hostname starting with %s", args[0])
+		}
+		if len(matches) > 1 {
+			return fmt.Errorf("Agent %s is ambiguous, it matches: %s", args[0], strings.Join(matches, ", "))
+		}
+
+		fmt.Printf("Agent %s (%s), active: %v\n", agentInfo.Hostname, agentInfo.ID.Value, active)
+		tasks, executors, err := printAgentState(state, agentInfo.ID.Value)
+		if err != nil {
+			return err
+		}
+		if active && !masterMarkAgentGoneOpts.force {
+			return fmt.Errorf("Agent %s is registered and active, drain or deactivate it first (or use --force)", agentInfo.Hostname)
+		}
+		if !masterMarkAgentGoneOpts.yes &&
+			!confirm(fmt.Sprintf("\nMark agent %s gone with %d tasks and %d executors? This is irreversible.", agentInfo.Hostname, tasks, executors)) {
+			return fmt.Errorf("Aborted")
+		}
+
+		return sendMasterCommand(masterCli, calls.MarkAgentGone(agentInfo.ID))
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterMarkAgentGoneCmd)
+	masterMarkAgentGoneCmd.Flags().BoolVarP(&masterMarkAgentGoneOpts.yes, "yes", "y", false, "do not ask for confirmation")
+	masterMarkAgentGoneCmd.Flags().BoolVar(&masterMarkAgentGoneOpts.force, "force", false, "mark the agent gone even if it is registered and active")
+}
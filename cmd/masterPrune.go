@@ -0,0 +1,95 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/spf13/cobra"
+)
+
+type masterPruneOptions struct {
+	exclude  []string
+	agent    string
+	parallel int
+}
+
+var masterPruneOpts = &masterPruneOptions{}
+
+var masterPruneCalls = MasterCallsDef{
+	"images": MasterCallDef{
+		desc: "Prunes the images not used by containers on all the registered agents (PRUNE_IMAGES).",
+	},
+}
+
+var masterPruneCmd = &cobra.Command{
+	Use:     "prune [call]",
+	Short:   "Prune on all agents",
+	Example: "prune images --parallel 20 --exclude mesosphere/mesos-slave:latest",
+	Long:    masterPruneCalls.describeCalls(),
+	Args:    masterPruneCalls.validateArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if masterPruneOpts.parallel < 1 {
+			return fmt.Errorf("--parallel must be at least 1")
+		}
+		agents, err := findAgents(masterCli, masterPruneOpts.agent)
+		if err != nil {
+			return err
+		}
+		excluded := parseImages(masterPruneOpts.exclude)
+
+		mutex := &sync.Mutex{}
+		failed := 0
+		slots := make(chan bool, masterPruneOpts.parallel)
+		var wg sync.WaitGroup
+		for _, a := range agents {
+			wg.Add(1)
+			slots <- true
+			go func(info mesos.AgentInfo) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				cli, err := registeredAgentCli(info)
+				if err == nil {
+					err = pruneImages(cli, excluded)
+				}
+				mutex.Lock()
+				defer mutex.Unlock()
+				if err != nil {
+					fmt.Printf("%s: error: %s\n", info.Hostname, err)
+					failed++
+					return
+				}
+				fmt.Printf("%s: pruned\n", info.Hostname)
+			}(a.GetAgentInfo())
+		}
+		wg.Wait()
+		if failed > 0 {
+			return fmt.Errorf("Failed to prune images on %d of %d agents", failed, len(agents))
+		}
+		return nil
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterPruneCmd)
+	masterPruneCmd.Flags().StringSliceVar(&masterPruneOpts.exclude, "exclude", []string{}, "images to keep, as [docker:|appc:]name (default docker)")
+	masterPruneCmd.Flags().StringVar(&masterPruneOpts.agent, "agent", "", "only prune agents whose id or hostname starts with this prefix")
+	masterPruneCmd.Flags().IntVar(&masterPruneOpts.parallel, "parallel", 10, "number of agents to prune at once")
+}
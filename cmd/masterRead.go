@@ -16,32 +16,20 @@ limitations under the License.
 package cmd
 
 import (
-	"context"
-	"fmt"
-	"strings"
+	"os"
 
-	"github.com/mesos/mesos-go/api/v1/lib/master"
 	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
 	"github.com/spf13/cobra"
 )
 
 type masterReadOptions struct {
-	path   string
-	length uint64
-	offset uint64
+	readFileOptions
 }
 
 var masterReadOpts = &masterReadOptions{}
 
 var masterReadCalls = MasterCallsDef{
 	"file": MasterCallDef{
-		call: func() *master.Call {
-			if masterReadOpts.length > 0 {
-				return calls.ReadFile(masterReadOpts.path, masterReadOpts.offset)
-			} else {
-				return calls.ReadFileWithLength(masterReadOpts.path, masterReadOpts.offset, masterReadOpts.length)
-			}
-		},
 		desc: "Reads data from a file on the master.",
 	},
 }
@@ -53,28 +41,18 @@ var masterReadCmd = &cobra.Command{
 	Long:  masterReadCalls.describeCalls(),
 	Args:  masterReadCalls.validateArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		key := strings.Join(args, " ")
-		resp, err := masterCli.Send(context.Background(), calls.NonStreaming(masterReadCalls[key].call()))
-		defer func() {
-			if resp != nil {
-				resp.Close()
+		read := func(path string, offset uint64, length uint64) ([]byte, uint64, error) {
+			r, err := sendMasterCall(masterCli, calls.ReadFileWithLength(path, offset, length))
+			if err != nil {
+				return nil, 0, err
 			}
-		}()
-		if err != nil {
-			return fmt.Errorf("Error sending call: %s", err)
-		}
-		var e master.Response
-		err = resp.Decode(&e)
-		if err != nil {
-			return fmt.Errorf("Error decoding response: %s", err)
+			return r.GetReadFile().GetData(), r.GetReadFile().GetSize(), nil
 		}
-		return nil
+		return readFile(read, masterReadOpts.readFileOptions, os.Stdout)
 	},
 }
 
 func init() {
 	masterCmd.AddCommand(masterReadCmd)
-	masterReadCmd.Flags().StringVar(&masterReadOpts.path, "path", "", "path to list files")
-	masterReadCmd.Flags().Uint64Var(&masterReadOpts.offset, "offset", 0, "the offset to start reading")
-	masterReadCmd.Flags().Uint64Var(&masterReadOpts.length, "length", 0, "the maximum number of bytes to read")
+	addReadFileFlags(masterReadCmd, &masterReadOpts.readFileOptions)
 }
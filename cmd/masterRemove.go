@@ -0,0 +1,112 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/mesos/mesos-go/api/v1/lib/quota"
+	"github.com/spf13/cobra"
+)
+
+type masterRemoveOptions struct {
+	role string
+}
+
+var masterRemoveOpts = &masterRemoveOptions{}
+
+var masterRemoveCalls = MasterCallsDef{
+	"quota": MasterCallDef{
+		call: func() *master.Call {
+			return calls.RemoveQuota(masterRemoveOpts.role)
+		},
+		desc: "Removes the quota of a role.",
+	},
+}
+
+// roleQuota returns the GET_QUOTA response restricted to the role
+func roleQuota(role string) (*master.Response, error) {
+	r, err := sendMasterCall(masterCli, calls.GetQuota())
+	if err != nil {
+		return nil, err
+	}
+	if r.GetQuota == nil {
+		return nil, fmt.Errorf("No quota set for role %s", role)
+	}
+	infos := []quota.QuotaInfo{}
+	for _, info := range r.GetQuota.Status.Infos {
+		if info.GetRole() == role {
+			infos = append(infos, info)
+		}
+	}
+	configs := []quota.QuotaConfig{}
+	for _, config := range r.GetQuota.Status.Configs {
+		if config.GetRole() == role {
+			configs = append(configs, config)
+		}
+	}
+	if len(infos) == 0 && len(configs) == 0 {
+		return nil, fmt.Errorf("No quota set for role %s", role)
+	}
+	r.GetQuota.Status.Infos = infos
+	r.GetQuota.Status.Configs = configs
+	return r, nil
+}
+
+// masterRemoveCmd represents the masterRemove command
+var masterRemoveCmd = &cobra.Command{
+	Use:     "remove [call]",
+	Short:   "Remove on master",
+	Example: "remove quota --role eng/backend",
+	Long:    masterRemoveCalls.describeCalls(),
+	Args:    masterRemoveCalls.validateArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := strings.Join(args, " ")
+		var removed *master.Response
+		if key == "quota" {
+			if masterRemoveOpts.role == "" {
+				return fmt.Errorf("Missing --role to remove quota")
+			}
+			var err error
+			if removed, err = roleQuota(masterRemoveOpts.role); err != nil {
+				return err
+			}
+		}
+		resp, err := masterCli.Send(context.Background(), calls.NonStreaming(masterRemoveCalls[key].call()))
+		defer func() {
+			if resp != nil {
+				resp.Close()
+			}
+		}()
+		if err != nil {
+			return fmt.Errorf("Error sending call: %s", err)
+		}
+		if removed != nil {
+			fmt.Println("Removed quota:")
+			return masterGetCalls["quota"].print(removed, "table")
+		}
+		return nil
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterRemoveCmd)
+	masterRemoveCmd.Flags().StringVar(&masterRemoveOpts.role, "role", "", "quota role, hierarchical roles are separated by / (see --help)")
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/spf13/cobra"
+)
+
+type masterReserveOptions struct {
+	agent       string
+	role        string
+	parentRoles []string
+	principal   string
+	labels      string
+	resources   string
+}
+
+var masterReserveOpts = &masterReserveOptions{}
+
+// reservedResources builds the resources with their whole reservation stack:
+// the reservations being refined (if any) followed by the one for --role
+func (o *masterReserveOptions) reservedResources() ([]mesos.Resource, error) {
+	if o.role == "" {
+		return nil, fmt.Errorf("Missing --role")
+	}
+	res, err := parseResources(o.resources)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := parseLabels(o.labels)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := []mesos.Resource_ReservationInfo{}
+	for _, parent := range o.parentRoles {
+		pr := strings.SplitN(parent, ":", 2)
+		role := pr[0]
+		if len(pr) == 1 {
+			reservations = append(reservations, mesos.Resource_ReservationInfo{
+				Type: mesos.Resource_ReservationInfo_STATIC.Enum(),
+				Role: &role,
+			})
+		} else {
+			principal := pr[1]
+			reservations = append(reservations, mesos.Resource_ReservationInfo{
+				Type:      mesos.Resource_ReservationInfo_DYNAMIC.Enum(),
+				Role:      &role,
+				Principal: &principal,
+			})
+		}
+	}
+	reservation := mesos.Resource_ReservationInfo{
+		Type:   mesos.Resource_ReservationInfo_DYNAMIC.Enum(),
+		Role:   &o.role,
+		Labels: labels,
+	}
+	if o.principal != "" {
+		reservation.Principal = &o.principal
+	}
+	reservations = append(reservations, reservation)
+
+	for i := range res {
+		res[i].Reservations = reservations
+	}
+	return res, nil
+}
+
+func sendReservation(call func(mesos.AgentID, ...mesos.Resource) *master.Call) error {
+	if masterReserveOpts.agent == "" {
+		return fmt.Errorf("Missing --agent")
+	}
+	res, err := masterReserveOpts.reservedResources()
+	if err != nil {
+		return err
+	}
+	a, err := findAgent(masterCli, masterReserveOpts.agent)
+	if err != nil {
+		return err
+	}
+	if verbose {
+		fmt.Printf("Using agent %s (%s)\n", a.GetAgentInfo().Hostname, a.GetAgentInfo().ID.Value)
+	}
+	return sendMasterCommand(masterCli, call(a.GetAgentInfo().ID, res...))
+}
+
+var masterReserveCmd = &cobra.Command{
+	Use:     "reserve",
+	Short:   "Dynamically reserve resources on an agent",
+	Example: "reserve --agent mesos-agent123 --role tenant --resources 'cpus:4,mem:8192,ports:[31000-31100]'",
+	Long: `Dynamically reserve resources on an agent for a role (RESERVE_RESOURCES).
+
+Resources are in the format 'name:value[,name:value...]' where value is a scalar (cpus:4),
+ranges (ports:[31000-31100]) or a set ({a,b}).
+
+To refine an existing reservation, pass the reservations being refined with --parent-role,
+from the outermost to the innermost one, as 'role' for a static reservation or
+'role:principal' for a dynamic one. --role must then be a sub-role of the last parent.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendReservation(calls.ReserveResources)
+	},
+}
+
+var masterUnreserveCmd = &cobra.Command{
+	Use:     "unreserve",
+	Short:   "Unreserve dynamically reserved resources on an agent",
+	Example: "unreserve --agent mesos-agent123 --role tenant --resources 'cpus:4,mem:8192,ports:[31000-31100]'",
+	Long: `Unreserve resources dynamically reserved on an agent for a role (UNRESERVE_RESOURCES).
+
+Resources, principal and labels must match the ones used at reservation time.
+For refined reservations, only the reservation for --role is removed, the ones
+passed with --parent-role are kept.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendReservation(calls.UnreserveResources)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{masterReserveCmd, masterUnreserveCmd} {
+		masterCmd.AddCommand(c)
+		c.Flags().StringVar(&masterReserveOpts.agent, "agent", "", "agent id or hostname prefix")
+		c.Flags().StringVar(&masterReserveOpts.role, "role", "", "reservation role")
+		c.Flags().StringSliceVar(&masterReserveOpts.parentRoles, "parent-role", []string{}, "reservations refined by --role, outermost first, as 'role' or 'role:principal' (see --help)")
+		c.Flags().StringVar(&masterReserveOpts.principal, "reservation-principal", "", "principal of the reservation")
+		c.Flags().StringVar(&masterReserveOpts.labels, "labels", "", "reservation labels in the format 'key=value[,key=value...]'")
+		c.Flags().StringVar(&masterReserveOpts.resources, "resources", "", "resources in the format 'name:value[,name:value...]' (example: 'cpus:4,mem:8192,ports:[31000-31100]')")
+	}
+}
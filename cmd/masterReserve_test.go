@@ -0,0 +1,94 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+)
+
+func TestSplitResources(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"cpus:4", []string{"cpus:4"}},
+		{"cpus:4,mem:8192", []string{"cpus:4", "mem:8192"}},
+		{"cpus:4,ports:[31000-31100,32000-32100]", []string{"cpus:4", "ports:[31000-31100,32000-32100]"}},
+		{"gpus:{a,b},cpus:1", []string{"gpus:{a,b}", "cpus:1"}},
+		{"", []string{""}},
+	}
+	for _, test := range tests {
+		if got := splitResources(test.in); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitResources(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestParseResources(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []mesos.Resource
+		wantErr bool
+	}{
+		{
+			in: "cpus:4, mem:8192.5",
+			want: []mesos.Resource{
+				{Name: "cpus", Type: mesos.SCALAR.Enum(), Scalar: &mesos.Value_Scalar{Value: 4}},
+				{Name: "mem", Type: mesos.SCALAR.Enum(), Scalar: &mesos.Value_Scalar{Value: 8192.5}},
+			},
+		},
+		{
+			in: "ports:[31000-31100, 32000]",
+			want: []mesos.Resource{
+				{Name: "ports", Type: mesos.RANGES.Enum(), Ranges: &mesos.Value_Ranges{Range: []mesos.Value_Range{
+					{Begin: 31000, End: 31100},
+					{Begin: 32000, End: 32000},
+				}}},
+			},
+		},
+		{
+			in: "gpus:{a, b}",
+			want: []mesos.Resource{
+				{Name: "gpus", Type: mesos.SET.Enum(), Set: &mesos.Value_Set{Item: []string{"a", "b"}}},
+			},
+		},
+		{in: "", wantErr: true},
+		{in: "cpus", wantErr: true},
+		{in: ":4", wantErr: true},
+		{in: "cpus:four", wantErr: true},
+		{in: "ports:[a-b]", wantErr: true},
+		{in: "ports:[1-b]", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := parseResources(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseResources(%q) = %v, want an error", test.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseResources(%q) failed: %s", test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseResources(%q) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/spf13/cobra"
+)
+
+type masterResourceProviderOptions struct {
+	yes bool
+}
+
+var masterResourceProviderOpts = masterResourceProviderOptions{}
+
+var masterResourceProviderCmd = &cobra.Command{
+	Use:   "resource-provider",
+	Short: "Manage resource providers",
+	Long:  "Manage resource providers.",
+}
+
+var masterResourceProviderMarkGoneCmd = &cobra.Command{
+	Use:     "mark-gone [resource-provider-id]",
+	Short:   "Mark a resource provider as gone",
+	Example: "resource-provider mark-gone 6ae5d0f8-0a0d-4c2a-b1a3-8e0e0c1c9b7e",
+	Long: `Mark a resource provider as gone (MARK_RESOURCE_PROVIDER_GONE), once its config has been
+removed from the agent. Its resources are removed from the cluster. This is irreversible.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !masterResourceProviderOpts.yes &&
+			!confirm(fmt.Sprintf("Mark resource provider %s gone? This is irreversible.", args[0])) {
+			return fmt.Errorf("Aborted")
+		}
+		call := &master.Call{
+			Type: master.Call_MARK_RESOURCE_PROVIDER_GONE,
+			MarkResourceProviderGone: &master.Call_MarkResourceProviderGone{
+				ResourceProviderID: mesos.ResourceProviderID{Value: args[0]},
+			},
+		}
+		return sendMasterCommand(masterCli, call)
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterResourceProviderCmd)
+	masterResourceProviderCmd.AddCommand(masterResourceProviderMarkGoneCmd)
+	masterResourceProviderMarkGoneCmd.Flags().BoolVarP(&masterResourceProviderOpts.yes, "yes", "y", false, "do not ask for confirmation")
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/spf13/cobra"
+)
+
+type masterTeardownOptions struct {
+	yes bool
+}
+
+var masterTeardownOpts = &masterTeardownOptions{}
+
+// findFramework returns the framework whose id or name starts with name,
+// failing if name matches none or more than one framework
+func findFramework(state *master.Response_GetState, name string) (*mesos.FrameworkInfo, error) {
+	matches := []mesos.FrameworkInfo{}
+	for _, f := range state.GetGetFrameworks().GetFrameworks() {
+		fi := f.GetFrameworkInfo()
+		if strings.HasPrefix(fi.GetID().GetValue(), name) || strings.HasPrefix(fi.GetName(), name) {
+			matches = append(matches, fi)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("Unable to find framework with id or name starting with %s", name)
+	}
+	if len(matches) > 1 {
+		frameworks := []string{}
+		for _, fi := range matches {
+			frameworks = append(frameworks, fmt.Sprintf("%s (%s)", fi.GetName(), fi.GetID().GetValue()))
+		}
+		return nil, fmt.Errorf("Framework %s is ambiguous, it matches: %s", name, strings.Join(frameworks, ", "))
+	}
+	return &matches[0], nil
+}
+
+var masterTeardownCmd = &cobra.Command{
+	Use:     "teardown [framework]",
+	Short:   "Tear down a framework",
+	Example: "teardown marathon_demo",
+	Long: `Tear down a framework (TEARDOWN): all its tasks and executors are killed and the
+framework is removed from the cluster. This is irreversible.
+
+Framework is a framework id or name prefix. The tasks, executors and agents affected are
+printed before asking for confirmation.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := masterState()
+		if err != nil {
+			return err
+		}
+		fi, err := findFramework(state, args[0])
+		if err != nil {
+			return err
+		}
+		frameworkID := fi.GetID().GetValue()
+
+		agents := map[string]bool{}
+		tasks := 0
+		for _, list := range [][]mesos.Task{state.GetGetTasks().GetTasks(), state.GetGetTasks().GetUnreachableTasks()} {
+			for _, t := range list {
+				if t.FrameworkID.Value == frameworkID {
+					tasks++
+					agents[t.GetAgentID().Value] = true
+				}
+			}
+		}
+		executors := 0
+		for _, e := range state.GetGetExecutors().GetExecutors() {
+			if e.GetExecutorInfo().GetFrameworkID().GetValue() == frameworkID {
+				executors++
+				agents[e.GetAgentID().Value] = true
+			}
+		}
+		hostnames := []string{}
+		for _, a := range state.GetGetAgents().GetAgents() {
+			if agents[a.GetAgentInfo().ID.Value] {
+				hostnames = append(hostnames, a.GetAgentInfo().Hostname)
+			}
+		}
+
+		roles := fi.GetRole()
+		if len(fi.GetRoles()) > 0 {
+			roles = strings.Join(fi.GetRoles(), ",")
+		}
+		fmt.Printf("Framework %s (%s), roles: %s, principal: %s\n", fi.GetName(), frameworkID, roles, fi.GetPrincipal())
+		fmt.Printf("Tasks: %d\nExecutors: %d\nAgents: %d\n", tasks, executors, len(agents))
+		if len(hostnames) > 0 {
+			fmt.Printf("  %s\n", strings.Join(hostnames, "\n  "))
+		}
+		if !masterTeardownOpts.yes &&
+			!confirm(fmt.Sprintf("\nTear down framework %s with %d tasks and %d executors on %d agents? This is irreversible.", fi.GetName(), tasks, executors, len(agents))) {
+			return fmt.Errorf("Aborted")
+		}
+
+		call := &master.Call{
+			Type:     master.Call_TEARDOWN,
+			Teardown: &master.Call_Teardown{FrameworkID: *fi.GetID()},
+		}
+		return sendMasterCommand(masterCli, call)
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterTeardownCmd)
+	masterTeardownCmd.Flags().BoolVarP(&masterTeardownOpts.yes, "yes", "y", false, "do not ask for confirmation")
+}
@@ -28,12 +28,17 @@ import (
 	"github.com/mesos/mesos-go/api/v1/lib/maintenance"
 	"github.com/mesos/mesos-go/api/v1/lib/master"
 	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/mesos/mesos-go/api/v1/lib/quota"
+	"github.com/mesos/mesos-go/api/v1/lib/resources"
 	"github.com/spf13/cobra"
 )
 
 type masterUpdateOptions struct {
 	weights          string
 	scheduleFilePath string
+	guarantees       []string
+	limits           []string
+	force            bool
 }
 
 var masterUpdateOpts = &masterUpdateOptions{}
@@ -92,7 +97,159 @@ var masterUpdateCalls = MasterCallsDef{
 		},
 		desc: "Updates the cluster’s maintenance schedule.",
 	},
-	//TODO: update quota
+	"quota": MasterCallDef{
+		call: func() *master.Call {
+			configs, err := quotaConfigs()
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+			return &master.Call{
+				Type: master.Call_UPDATE_QUOTA,
+				UpdateQuota: &master.Call_UpdateQuota{
+					Force:        &masterUpdateOpts.force,
+					QuotaConfigs: configs,
+				},
+			}
+		},
+		desc: "Updates quota guarantees and limits of one or more roles (before Mesos 1.9, replaces guarantees only).",
+	},
+}
+
+// parseRoleResources parses 'role=name:value[,name:value...]' scalar resources
+func parseRoleResources(s string) (string, map[string]mesos.Value_Scalar, error) {
+	rr := strings.SplitN(s, "=", 2)
+	if len(rr) != 2 || rr[0] == "" {
+		return "", nil, fmt.Errorf("Bad quota format for %s expecting <role>=<name>:<value>[,<name>:<value>...]", s)
+	}
+	res, err := parseResources(rr[1])
+	if err != nil {
+		return "", nil, err
+	}
+	scalars := map[string]mesos.Value_Scalar{}
+	for _, r := range res {
+		if r.GetType() != mesos.SCALAR {
+			return "", nil, fmt.Errorf("Quota of %s must be a scalar", r.GetName())
+		}
+		scalars[r.GetName()] = *r.GetScalar()
+	}
+	return rr[0], scalars, nil
+}
+
+// quotaConfigs builds quota configs from --guarantees and --limits
+func quotaConfigs() ([]quota.QuotaConfig, error) {
+	configs := map[string]*quota.QuotaConfig{}
+	roles := []string{}
+	config := func(role string) *quota.QuotaConfig {
+		if _, ok := configs[role]; !ok {
+			configs[role] = &quota.QuotaConfig{
+				Role:       role,
+				Guarantees: map[string]mesos.Value_Scalar{},
+				Limits:     map[string]mesos.Value_Scalar{},
+			}
+			roles = append(roles, role)
+		}
+		return configs[role]
+	}
+	for _, g := range masterUpdateOpts.guarantees {
+		role, scalars, err := parseRoleResources(g)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range scalars {
+			config(role).Guarantees[name] = value
+		}
+	}
+	for _, l := range masterUpdateOpts.limits {
+		role, scalars, err := parseRoleResources(l)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range scalars {
+			config(role).Limits[name] = value
+		}
+	}
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("Missing --guarantees or --limits")
+	}
+	result := []quota.QuotaConfig{}
+	for _, role := range roles {
+		result = append(result, *configs[role])
+	}
+	return result, nil
+}
+
+// masterVersionAtLeast returns true if the master version is at least major.minor
+func masterVersionAtLeast(major, minor int) (bool, error) {
+	r, err := sendMasterCall(masterCli, calls.GetVersion())
+	if err != nil {
+		return false, err
+	}
+	version := r.GetGetVersion().VersionInfo.GetVersion()
+	var vMajor, vMinor int
+	if _, err := fmt.Sscanf(version, "%d.%d", &vMajor, &vMinor); err != nil {
+		return false, fmt.Errorf("Cannot parse master version %s: %s", version, err)
+	}
+	return vMajor > major || vMajor == major && vMinor >= minor, nil
+}
+
+// legacyUpdateQuota replaces quota guarantees with REMOVE_QUOTA and SET_QUOTA for masters older than 1.9
+func legacyUpdateQuota() error {
+	configs, err := quotaConfigs()
+	if err != nil {
+		return err
+	}
+	r, err := sendMasterCall(masterCli, calls.GetQuota())
+	if err != nil {
+		return err
+	}
+	existing := map[string][]mesos.Resource{}
+	for _, info := range r.GetGetQuota().GetStatus().Infos {
+		existing[info.GetRole()] = info.GetGuarantee()
+	}
+	for _, c := range configs {
+		role := c.Role
+		if len(c.Limits) > 0 {
+			fmt.Printf("Ignoring limits of role %s, quota limits require Mesos 1.9+\n", role)
+		}
+		if len(c.Guarantees) == 0 {
+			continue
+		}
+		// SET_QUOTA fails if the role already has a quota, so it has to be removed first
+		previous, hasQuota := existing[role]
+		if hasQuota {
+			if err := sendMasterCommand(masterCli, calls.RemoveQuota(role)); err != nil {
+				return fmt.Errorf("Error removing quota of role %s: %s", role, err)
+			}
+		}
+		guarantee := []mesos.Resource{}
+		for name, value := range c.Guarantees {
+			guarantee = append(guarantee, resources.Build().Name(resources.Name(name)).Scalar(value.Value).Resource)
+		}
+		err := sendMasterCommand(masterCli, calls.SetQuota(quota.QuotaRequest{
+			Force:     &masterUpdateOpts.force,
+			Role:      &role,
+			Guarantee: guarantee,
+		}))
+		if err == nil {
+			continue
+		}
+		if !hasQuota {
+			return fmt.Errorf("Error setting quota of role %s: %s", role, err)
+		}
+		force := true
+		rerr := sendMasterCommand(masterCli, calls.SetQuota(quota.QuotaRequest{
+			Force:     &force,
+			Role:      &role,
+			Guarantee: previous,
+		}))
+		if rerr != nil {
+			return fmt.Errorf("Error setting quota of role %s: %s, and error restoring its previous quota %s: %s. The role has no quota anymore",
+				role, err, mesos.Resources(previous), rerr)
+		}
+		return fmt.Errorf("Error setting quota of role %s: %s, its previous quota was restored", role, err)
+	}
+	return nil
 }
 
 var masterUpdateCmd = &cobra.Command{
@@ -102,7 +259,21 @@ var masterUpdateCmd = &cobra.Command{
 	Args:  masterUpdateCalls.validateArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key := strings.Join(args, " ")
-		resp, err := masterCli.Send(context.Background(), calls.NonStreaming(masterUpdateCalls[key].call()))
+		if key == "quota" {
+			supported, err := masterVersionAtLeast(1, 9)
+			if err != nil {
+				return err
+			}
+			if !supported {
+				fmt.Println("Master is older than 1.9 and does not support UPDATE_QUOTA, falling back to REMOVE_QUOTA and SET_QUOTA")
+				return legacyUpdateQuota()
+			}
+		}
+		call := masterUpdateCalls[key].call()
+		if call == nil {
+			return fmt.Errorf("Invalid call parameters, try with --help")
+		}
+		resp, err := masterCli.Send(context.Background(), calls.NonStreaming(call))
 		defer func() {
 			if resp != nil {
 				resp.Close()
@@ -124,5 +295,8 @@ func init() {
 	masterCmd.AddCommand(masterUpdateCmd)
 	masterUpdateCmd.Flags().StringVar(&masterUpdateOpts.weights, "weights", "", "weight infos to update 'role:weight[,role:weight...]' (see --help)")
 	masterUpdateCmd.Flags().StringVar(&masterUpdateOpts.scheduleFilePath, "schedule", "", "file path of maintenance schedule JSON (see --help)")
+	masterUpdateCmd.Flags().StringArrayVar(&masterUpdateOpts.guarantees, "guarantees", []string{}, "quota guarantees 'role=name:value[,name:value...]', can be repeated for several roles (see --help)")
+	masterUpdateCmd.Flags().StringArrayVar(&masterUpdateOpts.limits, "limits", []string{}, "quota limits 'role=name:value[,name:value...]', can be repeated for several roles (see --help)")
+	masterUpdateCmd.Flags().BoolVar(&masterUpdateOpts.force, "force", false, "force quota update and don't check for overcommit (see --help)")
 
 }
@@ -0,0 +1,357 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type masterVolumeOptions struct {
+	agent                string
+	role                 string
+	reservationPrincipal string
+	source               string
+	sourceRoot           string
+	persistenceId        string
+	principal            string
+	containerPath        string
+	readOnly             bool
+	size                 float64
+	output               string
+}
+
+var masterVolumeOpts = &masterVolumeOptions{}
+
+// disk builds a disk resource of the given size reserved for --role on the --source disk
+func (o *masterVolumeOptions) disk(size float64) (mesos.Resource, error) {
+	disk := mesos.Resource{
+		Name:   "disk",
+		Type:   mesos.SCALAR.Enum(),
+		Scalar: &mesos.Value_Scalar{Value: size},
+		Disk:   &mesos.Resource_DiskInfo{},
+	}
+	if o.role == "" {
+		return disk, fmt.Errorf("Missing --role")
+	}
+	reservation := mesos.Resource_ReservationInfo{
+		Type: mesos.Resource_ReservationInfo_STATIC.Enum(),
+		Role: &o.role,
+	}
+	if o.reservationPrincipal != "" {
+		reservation.Type = mesos.Resource_ReservationInfo_DYNAMIC.Enum()
+		reservation.Principal = &o.reservationPrincipal
+	}
+	disk.Reservations = []mesos.Resource_ReservationInfo{reservation}
+
+	switch o.source {
+	case "root":
+	case "path":
+		disk.Disk.Source = &mesos.Resource_DiskInfo_Source{
+			Type: mesos.Resource_DiskInfo_Source_PATH.Enum(),
+			Path: &mesos.Resource_DiskInfo_Source_Path{Root: &o.sourceRoot},
+		}
+	case "mount":
+		disk.Disk.Source = &mesos.Resource_DiskInfo_Source{
+			Type:  mesos.Resource_DiskInfo_Source_MOUNT.Enum(),
+			Mount: &mesos.Resource_DiskInfo_Source_Mount{Root: &o.sourceRoot},
+		}
+	default:
+		return disk, fmt.Errorf("Unknown disk source %s, expected root, path or mount", o.source)
+	}
+	if o.source != "root" && o.sourceRoot == "" {
+		return disk, fmt.Errorf("Missing --source-root for %s disk source", o.source)
+	}
+	return disk, nil
+}
+
+// volume builds the persistent volume resource described by the options
+func (o *masterVolumeOptions) volume() (mesos.Resource, error) {
+	if o.persistenceId == "" {
+		return mesos.Resource{}, fmt.Errorf("Missing --persistence-id")
+	}
+	if o.containerPath == "" {
+		return mesos.Resource{}, fmt.Errorf("Missing --container-path")
+	}
+	if o.size <= 0 {
+		return mesos.Resource{}, fmt.Errorf("Missing --size")
+	}
+	volume, err := o.disk(o.size)
+	if err != nil {
+		return volume, err
+	}
+	volume.Disk.Persistence = &mesos.Resource_DiskInfo_Persistence{ID: o.persistenceId}
+	if o.principal != "" {
+		volume.Disk.Persistence.Principal = &o.principal
+	}
+	mode := mesos.RW
+	if o.readOnly {
+		mode = mesos.RO
+	}
+	volume.Disk.Volume = &mesos.Volume{
+		Mode:          mode.Enum(),
+		ContainerPath: o.containerPath,
+	}
+	return volume, nil
+}
+
+// agentVolumes returns the persistent volumes among the given resources
+func agentVolumes(res []mesos.Resource) []mesos.Resource {
+	volumes := []mesos.Resource{}
+	for _, r := range res {
+		if r.GetDisk().GetPersistence() != nil {
+			volumes = append(volumes, r)
+		}
+	}
+	return volumes
+}
+
+// findVolume returns the persistent volume with --persistence-id on the agent
+func findVolume(a *master.Response_GetAgents_Agent) (mesos.Resource, error) {
+	if masterVolumeOpts.persistenceId == "" {
+		return mesos.Resource{}, fmt.Errorf("Missing --persistence-id")
+	}
+	for _, v := range agentVolumes(a.GetTotalResources()) {
+		if v.GetDisk().GetPersistence().GetID() == masterVolumeOpts.persistenceId {
+			return v, nil
+		}
+	}
+	return mesos.Resource{}, fmt.Errorf("No persistent volume %s on agent %s", masterVolumeOpts.persistenceId, a.GetAgentInfo().Hostname)
+}
+
+// volumeInfo is a persistent volume of an agent, as listed by volume list
+type volumeInfo struct {
+	Agent         string  `json:"agent"`
+	Role          string  `json:"role"`
+	PersistenceID string  `json:"persistence_id"`
+	ContainerPath string  `json:"container_path"`
+	Source        string  `json:"source"`
+	Size          float64 `json:"size"`
+	Used          bool    `json:"used"`
+}
+
+// agentsVolumes returns the persistent volumes of the agents and whether they are used by a framework
+func agentsVolumes(agents []master.Response_GetAgents_Agent) []volumeInfo {
+	volumes := []volumeInfo{}
+	for _, a := range agents {
+		used := map[string]bool{}
+		for _, v := range agentVolumes(a.GetAllocatedResources()) {
+			used[v.GetDisk().GetPersistence().GetID()] = true
+		}
+		for _, v := range agentVolumes(a.GetTotalResources()) {
+			role := v.GetRole()
+			if len(v.GetReservations()) > 0 {
+				role = v.GetReservations()[len(v.GetReservations())-1].GetRole()
+			}
+			source := "root"
+			switch s := v.GetDisk().GetSource(); s.GetType() {
+			case mesos.Resource_DiskInfo_Source_PATH:
+				source = fmt.Sprintf("path:%s", s.GetPath().GetRoot())
+			case mesos.Resource_DiskInfo_Source_MOUNT:
+				source = fmt.Sprintf("mount:%s", s.GetMount().GetRoot())
+			}
+			id := v.GetDisk().GetPersistence().GetID()
+			volumes = append(volumes, volumeInfo{
+				Agent:         a.GetAgentInfo().Hostname,
+				Role:          role,
+				PersistenceID: id,
+				ContainerPath: v.GetDisk().GetVolume().GetContainerPath(),
+				Source:        source,
+				Size:          v.GetScalar().GetValue(),
+				Used:          used[id],
+			})
+		}
+	}
+	return volumes
+}
+
+func printVolumes(volumes []volumeInfo, format string) {
+	table := newTable(format)
+	table.SetHeader([]string{"agent", "role", "persistence_id", "container_path", "source", "size", "used"})
+	for _, v := range volumes {
+		table.Append([]string{
+			v.Agent,
+			v.Role,
+			v.PersistenceID,
+			v.ContainerPath,
+			v.Source,
+			fmt.Sprintf("%.0f", v.Size),
+			fmt.Sprintf("%v", v.Used),
+		})
+	}
+	table.SetBorder(false)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
+
+// sendVolumeCall sends the call built for the --agent agent, then prints its volumes
+func sendVolumeCall(call func(a *master.Response_GetAgents_Agent) (*master.Call, error)) error {
+	if masterVolumeOpts.agent == "" {
+		return fmt.Errorf("Missing --agent")
+	}
+	a, err := findAgent(masterCli, masterVolumeOpts.agent)
+	if err != nil {
+		return err
+	}
+	c, err := call(a)
+	if err != nil {
+		return err
+	}
+	if err = sendMasterCommand(masterCli, c); err != nil {
+		return err
+	}
+	a, err = findAgent(masterCli, a.GetAgentInfo().ID.Value)
+	if err != nil {
+		return err
+	}
+	printVolumes(agentsVolumes([]master.Response_GetAgents_Agent{*a}), "table")
+	return nil
+}
+
+var masterVolumeCmd = &cobra.Command{
+	Use:   "volume",
+	Short: "Manage persistent volumes",
+	Long: `Manage persistent volumes (CREATE_VOLUMES, DESTROY_VOLUMES, GROW_VOLUME and SHRINK_VOLUME).
+
+Volumes are created on disk reserved for --role: statically by default, or dynamically
+with --reservation-principal. Sizes are in MB.`,
+}
+
+var masterVolumeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List persistent volumes",
+	Long:  "List persistent volumes of all agents, or of --agent, and whether they are used by a framework",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, tmpl, err := parseOutput(masterVolumeOpts.output)
+		if err != nil {
+			return err
+		}
+		agents, err := findAgents(masterCli, masterVolumeOpts.agent)
+		if err != nil {
+			return err
+		}
+		volumes := agentsVolumes(agents)
+		if !isTableOutput(format) {
+			return printJSON(volumes, format, tmpl)
+		}
+		printVolumes(volumes, format)
+		return nil
+	},
+}
+
+var masterVolumeCreateCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Create a persistent volume",
+	Example: "volume create --agent mesos-agent123 --role db --persistence-id db-data --container-path data --size 10240",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		volume, err := masterVolumeOpts.volume()
+		if err != nil {
+			return err
+		}
+		return sendVolumeCall(func(a *master.Response_GetAgents_Agent) (*master.Call, error) {
+			return calls.CreateVolumes(a.GetAgentInfo().ID, volume), nil
+		})
+	},
+}
+
+var masterVolumeDestroyCmd = &cobra.Command{
+	Use:     "destroy",
+	Short:   "Destroy a persistent volume",
+	Example: "volume destroy --agent mesos-agent123 --persistence-id db-data",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendVolumeCall(func(a *master.Response_GetAgents_Agent) (*master.Call, error) {
+			volume, err := findVolume(a)
+			if err != nil {
+				return nil, err
+			}
+			return calls.DestroyVolumes(a.GetAgentInfo().ID, volume), nil
+		})
+	},
+}
+
+var masterVolumeGrowCmd = &cobra.Command{
+	Use:     "grow",
+	Short:   "Grow a persistent volume",
+	Example: "volume grow --agent mesos-agent123 --persistence-id db-data --size 1024",
+	Long:    "Grow a persistent volume by --size MB, taken from the disk reserved for the role of the volume",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if masterVolumeOpts.size <= 0 {
+			return fmt.Errorf("Missing --size")
+		}
+		return sendVolumeCall(func(a *master.Response_GetAgents_Agent) (*master.Call, error) {
+			volume, err := findVolume(a)
+			if err != nil {
+				return nil, err
+			}
+			addition := volume
+			addition.Scalar = &mesos.Value_Scalar{Value: masterVolumeOpts.size}
+			addition.Disk = &mesos.Resource_DiskInfo{Source: volume.GetDisk().GetSource()}
+			agentID := a.GetAgentInfo().ID
+			return calls.GrowVolume(&agentID, volume, addition), nil
+		})
+	},
+}
+
+var masterVolumeShrinkCmd = &cobra.Command{
+	Use:     "shrink",
+	Short:   "Shrink a persistent volume",
+	Example: "volume shrink --agent mesos-agent123 --persistence-id db-data --size 1024",
+	Long:    "Shrink a persistent volume by --size MB, given back to the disk reserved for the role of the volume",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if masterVolumeOpts.size <= 0 {
+			return fmt.Errorf("Missing --size")
+		}
+		return sendVolumeCall(func(a *master.Response_GetAgents_Agent) (*master.Call, error) {
+			volume, err := findVolume(a)
+			if err != nil {
+				return nil, err
+			}
+			agentID := a.GetAgentInfo().ID
+			return calls.ShrinkVolume(&agentID, volume, mesos.Value_Scalar{Value: masterVolumeOpts.size}), nil
+		})
+	},
+}
+
+func init() {
+	masterCmd.AddCommand(masterVolumeCmd)
+	masterVolumeCmd.AddCommand(masterVolumeListCmd, masterVolumeCreateCmd, masterVolumeDestroyCmd, masterVolumeGrowCmd, masterVolumeShrinkCmd)
+
+	masterVolumeCmd.PersistentFlags().StringVar(&masterVolumeOpts.agent, "agent", "", "agent id or hostname prefix")
+	masterVolumeCmd.PersistentFlags().StringVar(&masterVolumeOpts.persistenceId, "persistence-id", "", "persistence ID of the volume")
+	masterVolumeCmd.PersistentFlags().Float64Var(&masterVolumeOpts.size, "size", 0, "volume size in MB, or size to add/remove when growing/shrinking")
+
+	addOutputFlag(masterVolumeListCmd, &masterVolumeOpts.output)
+
+	masterVolumeCreateCmd.Flags().StringVar(&masterVolumeOpts.role, "role", "", "role the disk is reserved for")
+	masterVolumeCreateCmd.Flags().StringVar(&masterVolumeOpts.reservationPrincipal, "reservation-principal", "", "principal of the disk dynamic reservation (static reservation if not set)")
+	masterVolumeCreateCmd.Flags().StringVar(&masterVolumeOpts.source, "source", "root", "disk source: root, path or mount")
+	masterVolumeCreateCmd.Flags().StringVar(&masterVolumeOpts.sourceRoot, "source-root", "", "root directory of path or mount disk source")
+	masterVolumeCreateCmd.Flags().StringVar(&masterVolumeOpts.principal, "volume-principal", "", "principal of the volume creator")
+	masterVolumeCreateCmd.Flags().StringVar(&masterVolumeOpts.containerPath, "container-path", "", "path of the volume in containers, relative to their sandbox")
+	masterVolumeCreateCmd.Flags().BoolVar(&masterVolumeOpts.readOnly, "read-only", false, "mount the volume read only")
+}
@@ -0,0 +1,359 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// outputHelp is the help of the -o flag of get and list commands
+const outputHelp = "output format: table (default), json, yaml, csv, tsv, jsonpath=<template> (fields, [n] and [*] only, like {.tasks[*].task_id}) or go-template=<template>"
+
+// addOutputFlag adds the -o flag of get and list commands
+func addOutputFlag(cmd *cobra.Command, output *string) {
+	cmd.Flags().StringVarP(output, "output", "o", "", outputHelp)
+}
+
+// table is implemented by the tablewriter tables and by CSV/TSV tables
+type table interface {
+	SetHeader(keys []string)
+	Append(row []string)
+	SetBorder(border bool)
+	SetHeaderLine(line bool)
+	SetColumnSeparator(sep string)
+	SetAlignment(align int)
+	Render()
+}
+
+// newTable returns a table writing to stdout, aligned or as CSV/TSV depending on the output format
+func newTable(format string) table {
+	switch format {
+	case "csv":
+		return &csvTable{comma: ','}
+	case "tsv":
+		return &csvTable{comma: '\t'}
+	default:
+		return tablewriter.NewWriter(os.Stdout)
+	}
+}
+
+// csvTable writes the header and rows as CSV, ignoring the style settings
+type csvTable struct {
+	comma  rune
+	header []string
+	rows   [][]string
+}
+
+func (t *csvTable) SetHeader(keys []string)       { t.header = keys }
+func (t *csvTable) Append(row []string)           { t.rows = append(t.rows, row) }
+func (t *csvTable) SetBorder(border bool)         {}
+func (t *csvTable) SetHeaderLine(line bool)       {}
+func (t *csvTable) SetColumnSeparator(sep string) {}
+func (t *csvTable) SetAlignment(align int)        {}
+
+func (t *csvTable) Render() {
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = t.comma
+	if t.header != nil {
+		w.Write(t.header)
+	}
+	w.WriteAll(t.rows)
+}
+
+// printRecord prints a single record as key: value lines, or as a header and a row in CSV/TSV
+func printRecord(format string, keys []string, values []string) {
+	table := newTable(format)
+	if format == "table" {
+		for i, key := range keys {
+			table.Append([]string{key + ":", values[i]})
+		}
+	} else {
+		table.SetHeader(keys)
+		table.Append(values)
+	}
+	table.SetBorder(false)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
+
+// isCSVOutput returns true if the output format is CSV or TSV, which can only hold a single table
+func isCSVOutput(format string) bool {
+	return format == "csv" || format == "tsv"
+}
+
+// parseOutput splits the output flag in its format and template, if any
+func parseOutput(output string) (string, string, error) {
+	switch output {
+	case "":
+		return "table", "", nil
+	case "table", "json", "yaml", "csv", "tsv":
+		return output, "", nil
+	}
+	for _, format := range []string{"jsonpath", "go-template"} {
+		if strings.HasPrefix(output, format+"=") {
+			return format, strings.TrimPrefix(output, format+"="), nil
+		}
+	}
+	return "", "", fmt.Errorf("Unknown output format %s, expected one of: table, json, yaml, csv, tsv, jsonpath=..., go-template=...", output)
+}
+
+// parseOutputFlags is like parseOutput, the json flag being a shortcut for -o json
+func parseOutputFlags(output string, json bool) (string, string, error) {
+	if json {
+		if output != "" {
+			return "", "", fmt.Errorf("--json and -o are mutually exclusive")
+		}
+		return "json", "", nil
+	}
+	return parseOutput(output)
+}
+
+// isTableOutput returns true if the output format is printed by the call printers
+func isTableOutput(format string) bool {
+	return format == "table" || format == "csv" || format == "tsv"
+}
+
+// decodeJSON decodes JSON keeping integers as int64, as most Mesos ids and timestamps do not fit in a float64
+func decodeJSON(j []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(j))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return numbers(value), nil
+}
+
+func numbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = numbers(value)
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = numbers(value)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+// printStructured prints the JSON of a response in the json, yaml, jsonpath or go-template format
+func printStructured(j []byte, format string, tmpl string) error {
+	if format == "json" {
+		fmt.Println(string(j))
+		return nil
+	}
+	value, err := decodeJSON(j)
+	if err != nil {
+		return fmt.Errorf("Error decoding JSON: %s", err)
+	}
+	switch format {
+	case "yaml":
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("Error marshalling response as YAML: %s", err)
+		}
+		fmt.Print(string(out))
+	case "jsonpath":
+		out, err := executeJSONPath(value, tmpl)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+	case "go-template":
+		t, err := template.New("output").Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("Error parsing template: %s", err)
+		}
+		if err = t.Execute(os.Stdout, value); err != nil {
+			return fmt.Errorf("Error executing template: %s", err)
+		}
+	}
+	return nil
+}
+
+// printJSON prints the value marshalled as JSON in the json, yaml, jsonpath or go-template format
+func printJSON(value interface{}, format string, tmpl string) error {
+	j, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error marshalling response as JSON: %s", err)
+	}
+	return printStructured(j, format, tmpl)
+}
+
+// executeJSONPath executes a JSONPath template: the {expressions} are replaced by their results
+// separated by spaces, and {"literals"} by their value. Unlike kubectl, only a subset of JSONPath
+// is supported: fields (.name), indexes ([0], [-1]) and wildcards ([*]), optionally after $.
+// Filters, recursive descent, slices, unions and range/end are not.
+func executeJSONPath(value interface{}, tmpl string) (string, error) {
+	out := ""
+	for tmpl != "" {
+		start := strings.Index(tmpl, "{")
+		if start < 0 {
+			out += tmpl
+			break
+		}
+		end := strings.Index(tmpl[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("Unclosed expression in JSONPath template: %s", tmpl[start:])
+		}
+		out += tmpl[:start]
+		expr := strings.TrimSpace(tmpl[start+1 : start+end])
+		tmpl = tmpl[start+end+1:]
+		if strings.HasPrefix(expr, "\"") {
+			literal, err := strconv.Unquote(expr)
+			if err != nil {
+				return "", fmt.Errorf("Bad JSONPath literal %s: %s", expr, err)
+			}
+			out += literal
+			continue
+		}
+		values, err := evalJSONPath(value, expr)
+		if err != nil {
+			return "", err
+		}
+		results := []string{}
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				results = append(results, s)
+				continue
+			}
+			j, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			results = append(results, string(j))
+		}
+		out += strings.Join(results, " ")
+	}
+	return out, nil
+}
+
+// evalJSONPath evaluates a JSONPath expression made of fields (.name), indexes ([0], [-1]) and
+// wildcards ([*]) only. Missing fields and indexes out of range select nothing, but any other
+// syntax, a field of a value which is not an object or an index of a value which is not an array
+// is an error.
+func evalJSONPath(value interface{}, expr string) ([]interface{}, error) {
+	path := strings.TrimPrefix(expr, "$")
+	if path == "." {
+		path = ""
+	}
+	values := []interface{}{value}
+	for path != "" {
+		next := []interface{}{}
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			name := path[:end]
+			path = path[end:]
+			if name == "" || strings.ContainsAny(name, jsonPathUnsupported) {
+				return nil, unsupportedJSONPath(expr)
+			}
+			for _, v := range values {
+				switch v := v.(type) {
+				case nil:
+				case map[string]interface{}:
+					if field, ok := v[name]; ok {
+						next = append(next, field)
+					}
+				default:
+					return nil, fmt.Errorf("Unable to get field %s of a value which is not an object in JSONPath expression %s", name, expr)
+				}
+			}
+		case '[':
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("Unclosed index in JSONPath expression %s", expr)
+			}
+			index := path[1:end]
+			path = path[end+1:]
+			i, err := strconv.Atoi(index)
+			if index != "*" && err != nil {
+				return nil, unsupportedJSONPath(expr)
+			}
+			for _, v := range values {
+				switch v := v.(type) {
+				case nil:
+				case []interface{}:
+					if index == "*" {
+						next = append(next, v...)
+						continue
+					}
+					j := i
+					if j < 0 {
+						j += len(v)
+					}
+					if j >= 0 && j < len(v) {
+						next = append(next, v[j])
+					}
+				case map[string]interface{}:
+					if index != "*" {
+						return nil, fmt.Errorf("Unable to get index %s of an object in JSONPath expression %s", index, expr)
+					}
+					keys := []string{}
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				default:
+					return nil, fmt.Errorf("Unable to get index %s of a value which is not an array in JSONPath expression %s", index, expr)
+				}
+			}
+		default:
+			return nil, unsupportedJSONPath(expr)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// jsonPathUnsupported are the characters of JSONPath syntaxes which are not supported in field names
+const jsonPathUnsupported = "*?@()'\" ,:"
+
+func unsupportedJSONPath(expr string) error {
+	return fmt.Errorf("Unsupported JSONPath expression %s, only fields (.name), indexes ([0], [-1]) and wildcards ([*]) are supported", expr)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"testing"
+)
+
+func TestExecuteJSONPath(t *testing.T) {
+	value, err := decodeJSON([]byte(`{
+		"tasks": [
+			{"task_id": "a", "agent_id": "s1", "resources": [{"name": "cpus"}]},
+			{"task_id": "b", "agent_id": "s2", "resources": []}
+		],
+		"m": {"y": 2, "x": 1},
+		"id": 12345678901234567,
+		"n": null
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{tmpl: "{.tasks[*].task_id}", want: "a b"},
+		{tmpl: "{$.tasks[0].agent_id}", want: "s1"},
+		{tmpl: "{.tasks[-1].task_id}", want: "b"},
+		{tmpl: "{.tasks[*].resources[*].name}", want: "cpus"},
+		{tmpl: "{.m[*]}", want: "1 2"},
+		{tmpl: "{.m}", want: `{"x":1,"y":2}`},
+		{tmpl: "{.id}", want: "12345678901234567"},
+		{tmpl: "{.n.x}", want: ""},
+		{tmpl: "{.missing}", want: ""},
+		{tmpl: "{.tasks[2].task_id}", want: ""},
+		{tmpl: "{.tasks[-3].task_id}", want: ""},
+		{tmpl: `id={.id}{"\n"}`, want: "id=12345678901234567\n"},
+		{tmpl: "{.tasks[0].task_id} on { .tasks[0].agent_id }", want: "a on s1"},
+		{tmpl: "{.m.x}", want: "1"},
+		{tmpl: "{$}", want: `{"id":12345678901234567,"m":{"x":1,"y":2},"n":null,"tasks":[{"agent_id":"s1","resources":[{"name":"cpus"}],"task_id":"a"},{"agent_id":"s2","resources":[],"task_id":"b"}]}`},
+		{tmpl: "{.}", want: `{"id":12345678901234567,"m":{"x":1,"y":2},"n":null,"tasks":[{"agent_id":"s1","resources":[{"name":"cpus"}],"task_id":"a"},{"agent_id":"s2","resources":[],"task_id":"b"}]}`},
+		{tmpl: "no expression", want: "no expression"},
+		{tmpl: "{..task_id}", wantErr: true},
+		{tmpl: "{.tasks[?(@.task_id==\"a\")]}", wantErr: true},
+		{tmpl: "{.tasks[0:1]}", wantErr: true},
+		{tmpl: "{.tasks[0,1]}", wantErr: true},
+		{tmpl: "{range .tasks[*]}{.task_id}{end}", wantErr: true},
+		{tmpl: "{.m['x']}", wantErr: true},
+		{tmpl: "{.tasks.task_id}", wantErr: true},
+		{tmpl: "{.m[0]}", wantErr: true},
+		{tmpl: "{.id[0]}", wantErr: true},
+		{tmpl: "{.id.x}", wantErr: true},
+		{tmpl: "{.tasks[0}", wantErr: true},
+		{tmpl: "{.tasks", wantErr: true},
+		{tmpl: `{"unterminated}`, wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := executeJSONPath(value, test.tmpl)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("executeJSONPath(%q) = %q, want an error", test.tmpl, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("executeJSONPath(%q) failed: %s", test.tmpl, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("executeJSONPath(%q) = %q, want %q", test.tmpl, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/maintenance"
+	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// clusterPolicy is the quotas, weights and maintenance configuration of a cluster,
+// as read by apply and written by export. Sections which are not set are not managed.
+type clusterPolicy struct {
+	Quotas      map[string]quotaPolicy `json:"quotas,omitempty" yaml:"quotas,omitempty"`
+	Weights     map[string]float64     `json:"weights,omitempty" yaml:"weights,omitempty"`
+	Maintenance *maintenancePolicy     `json:"maintenance,omitempty" yaml:"maintenance,omitempty"`
+}
+
+// exportedPolicy is a clusterPolicy where the maps are referenced, so that the sections
+// which are set are written even when they are empty, and then reconciled by apply
+type exportedPolicy struct {
+	Quotas      *map[string]quotaPolicy `json:"quotas,omitempty" yaml:"quotas,omitempty"`
+	Weights     *map[string]float64     `json:"weights,omitempty" yaml:"weights,omitempty"`
+	Maintenance *maintenancePolicy      `json:"maintenance,omitempty" yaml:"maintenance,omitempty"`
+}
+
+func (p clusterPolicy) exported() exportedPolicy {
+	exported := exportedPolicy{Maintenance: p.Maintenance}
+	if p.Quotas != nil {
+		exported.Quotas = &p.Quotas
+	}
+	if p.Weights != nil {
+		exported.Weights = &p.Weights
+	}
+	return exported
+}
+
+type quotaPolicy struct {
+	Guarantees map[string]float64 `json:"guarantees,omitempty" yaml:"guarantees,omitempty"`
+	Limits     map[string]float64 `json:"limits,omitempty" yaml:"limits,omitempty"`
+}
+
+type maintenancePolicy struct {
+	Windows []maintenanceWindowPolicy `json:"windows" yaml:"windows"`
+}
+
+type maintenanceWindowPolicy struct {
+	Machines []string `json:"machines" yaml:"machines"`
+	Start    string   `json:"start" yaml:"start"`
+	Duration string   `json:"duration" yaml:"duration"`
+}
+
+// readPolicy reads a YAML or JSON policy file
+func readPolicy(path string) (*clusterPolicy, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading file %s: %s", path, err)
+	}
+	policy := clusterPolicy{}
+	// JSON being a subset of YAML, both are parsed as YAML
+	if err = yaml.UnmarshalStrict(bytes, &policy); err != nil {
+		return nil, fmt.Errorf("Error parsing file %s: %s", path, err)
+	}
+	return &policy, nil
+}
+
+func (q quotaPolicy) String() string {
+	return fmt.Sprintf("guarantees %s limits %s", formatScalars(q.Guarantees), formatScalars(q.Limits))
+}
+
+func (q quotaPolicy) equal(o quotaPolicy) bool {
+	return equalScalars(q.Guarantees, o.Guarantees) && equalScalars(q.Limits, o.Limits)
+}
+
+func formatScalars(scalars map[string]float64) string {
+	if len(scalars) == 0 {
+		return "-"
+	}
+	names := []string{}
+	for name := range scalars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := []string{}
+	for _, name := range names {
+		values = append(values, fmt.Sprintf("%s:%s", name, strconv.FormatFloat(scalars[name], 'f', -1, 64)))
+	}
+	return strings.Join(values, ",")
+}
+
+func equalScalars(a, b map[string]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if v, ok := b[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// currentQuotas returns the quotas configured on the cluster (GET_QUOTA)
+func currentQuotas() (map[string]quotaPolicy, error) {
+	r, err := sendMasterCall(masterCli, calls.GetQuota())
+	if err != nil {
+		return nil, err
+	}
+	quotas := map[string]quotaPolicy{}
+	status := r.GetGetQuota().GetStatus()
+	for _, c := range status.Configs {
+		q := quotaPolicy{Guarantees: map[string]float64{}, Limits: map[string]float64{}}
+		for name, value := range c.GetGuarantees() {
+			q.Guarantees[name] = value.Value
+		}
+		for name, value := range c.GetLimits() {
+			q.Limits[name] = value.Value
+		}
+		quotas[c.GetRole()] = q
+	}
+	if len(status.Configs) == 0 {
+		for _, info := range status.Infos {
+			q := quotaPolicy{Guarantees: map[string]float64{}, Limits: map[string]float64{}}
+			for _, res := range info.GetGuarantee() {
+				q.Guarantees[res.GetName()] = res.GetScalar().GetValue()
+			}
+			quotas[info.GetRole()] = q
+		}
+	}
+	return quotas, nil
+}
+
+// currentWeights returns the role weights configured on the cluster (GET_WEIGHTS)
+func currentWeights() (map[string]float64, error) {
+	r, err := sendMasterCall(masterCli, calls.GetWeights())
+	if err != nil {
+		return nil, err
+	}
+	weights := map[string]float64{}
+	for _, w := range r.GetGetWeights().GetWeightInfos() {
+		weights[w.GetRole()] = w.GetWeight()
+	}
+	return weights, nil
+}
+
+// schedulePolicy converts a maintenance schedule to its policy
+func schedulePolicy(schedule maintenance.Schedule) *maintenancePolicy {
+	policy := &maintenancePolicy{Windows: []maintenanceWindowPolicy{}}
+	for _, w := range schedule.Windows {
+		window := maintenanceWindowPolicy{
+			Start:    time.Unix(0, w.Unavailability.Start.GetNanoseconds()).UTC().Format(time.RFC3339),
+			Duration: time.Duration(w.Unavailability.Duration.GetNanoseconds()).String(),
+		}
+		for _, m := range w.MachineIDs {
+			machine := m.GetHostname()
+			if m.GetIP() != "" {
+				machine = fmt.Sprintf("%s/%s", machine, m.GetIP())
+			}
+			window.Machines = append(window.Machines, machine)
+		}
+		sort.Strings(window.Machines)
+		policy.Windows = append(policy.Windows, window)
+	}
+	sort.SliceStable(policy.Windows, func(i, j int) bool {
+		return policy.Windows[i].Start < policy.Windows[j].Start
+	})
+	return policy
+}
+
+// schedule converts a maintenance policy to a maintenance schedule
+func (p *maintenancePolicy) schedule() (maintenance.Schedule, error) {
+	schedule := maintenance.Schedule{}
+	for _, w := range p.Windows {
+		start, err := parseTime(w.Start)
+		if err != nil {
+			return schedule, err
+		}
+		duration, err := time.ParseDuration(w.Duration)
+		if err != nil {
+			return schedule, fmt.Errorf("Bad duration format for %s: %s", w.Duration, err)
+		}
+		window := maintenance.Window{
+			Unavailability: mesos.Unavailability{
+				Start:    mesos.TimeInfo{Nanoseconds: start.UnixNano()},
+				Duration: &mesos.DurationInfo{Nanoseconds: duration.Nanoseconds()},
+			},
+		}
+		for _, spec := range w.Machines {
+			m, err := parseMachine(spec)
+			if err != nil {
+				return schedule, err
+			}
+			window.MachineIDs = append(window.MachineIDs, m)
+		}
+		schedule.Windows = append(schedule.Windows, window)
+	}
+	return schedule, nil
+}
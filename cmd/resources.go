@@ -0,0 +1,126 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+)
+
+// splitResources splits a resources string on commas which are not enclosed in [] or {}
+func splitResources(s string) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i, c := range s {
+		switch c {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseResources parses resources in the format 'name:value[,name:value...]' where value is
+// either a scalar (cpus:4), ranges (ports:[31000-31100,32000-32100]) or a set (gpus:{a,b})
+func parseResources(s string) ([]mesos.Resource, error) {
+	res := []mesos.Resource{}
+	for _, rs := range splitResources(s) {
+		rs = strings.TrimSpace(rs)
+		if rs == "" {
+			continue
+		}
+		r := strings.SplitN(rs, ":", 2)
+		if len(r) != 2 || r[0] == "" {
+			return nil, fmt.Errorf("Bad resource format for %s expecting <name>:<value>", rs)
+		}
+		name, value := r[0], strings.TrimSpace(r[1])
+		switch {
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			ranges := []mesos.Value_Range{}
+			for _, ra := range strings.Split(value[1:len(value)-1], ",") {
+				be := strings.SplitN(strings.TrimSpace(ra), "-", 2)
+				begin, err := strconv.ParseUint(be[0], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("Wrong range format %s for resource %s: %s", ra, name, err)
+				}
+				end := begin
+				if len(be) == 2 {
+					if end, err = strconv.ParseUint(be[1], 10, 64); err != nil {
+						return nil, fmt.Errorf("Wrong range format %s for resource %s: %s", ra, name, err)
+					}
+				}
+				ranges = append(ranges, mesos.Value_Range{Begin: begin, End: end})
+			}
+			res = append(res, mesos.Resource{
+				Name:   name,
+				Type:   mesos.RANGES.Enum(),
+				Ranges: &mesos.Value_Ranges{Range: ranges},
+			})
+		case strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}"):
+			items := []string{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+			res = append(res, mesos.Resource{
+				Name: name,
+				Type: mesos.SET.Enum(),
+				Set:  &mesos.Value_Set{Item: items},
+			})
+		default:
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Wrong resource scalar value format %s, error: %s", value, err)
+			}
+			res = append(res, mesos.Resource{
+				Name:   name,
+				Type:   mesos.SCALAR.Enum(),
+				Scalar: &mesos.Value_Scalar{Value: v},
+			})
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("No resources specified")
+	}
+	return res, nil
+}
+
+// parseLabels parses labels in the format 'key=value[,key=value...]'
+func parseLabels(s string) (*mesos.Labels, error) {
+	if s == "" {
+		return nil, nil
+	}
+	labels := mesos.Labels{}
+	for _, ls := range strings.Split(s, ",") {
+		l := strings.SplitN(ls, "=", 2)
+		if len(l) != 2 || l[0] == "" {
+			return nil, fmt.Errorf("Bad label format for %s expecting <key>=<value>", ls)
+		}
+		value := l[1]
+		labels.Labels = append(labels.Labels, mesos.Label{Key: l[0], Value: &value})
+	}
+	return &labels, nil
+}
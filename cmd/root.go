@@ -16,6 +16,7 @@ limitations under the License.
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"strings"
@@ -40,12 +41,16 @@ to interact with Apache Mesos clusters`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	// Special case for agent command
+	// Special case for agent command, only when it is the top level command
 	args := os.Args[1:]
 	for index, v := range args {
+		if v == "--" || isRootCommand(v) && v != "agent" {
+			break
+		}
 		if v == "agent" && len(args) > index+1 && !strings.HasPrefix(args[index+1], "-") {
 			agentOpts.name = args[index+1]
 			os.Args = append(os.Args[0:index+2], os.Args[index+3:]...)
+			break
 		}
 	}
 
@@ -55,6 +60,26 @@ func Execute() {
 	}
 }
 
+// confirm asks the question on the terminal and returns true if the answer is yes
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func isRootCommand(name string) bool {
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	cobra.OnInitialize(initConfig, presetRequiredFlags)
 
@@ -89,6 +114,6 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil && verbose {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
@@ -0,0 +1,241 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	mesos "github.com/mesos/mesos-go/api/v1/lib"
+	agentcalls "github.com/mesos/mesos-go/api/v1/lib/agent/calls"
+	"github.com/mesos/mesos-go/api/v1/lib/master"
+	"github.com/mesos/mesos-go/api/v1/lib/master/calls"
+)
+
+// taskSelector selects tasks with regular expressions matching the whole framework name or id,
+// task id and agent hostname or id. Unset expressions match everything.
+type taskSelector map[string]*regexp.Regexp
+
+var taskSelectorKeys = []string{"framework", "task", "agent"}
+
+// selectorTerm matches the start of the terms of a selector
+var selectorTerm = regexp.MustCompile(`^\w+=`)
+
+// splitSelector splits a selector on the commas followed by a key, so that regular
+// expressions can contain commas (task=web-[0-9]{1,3})
+func splitSelector(s string) []string {
+	terms := []string{}
+	for _, term := range strings.Split(s, ",") {
+		if len(terms) > 0 && !selectorTerm.MatchString(term) {
+			terms[len(terms)-1] += "," + term
+			continue
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// parseSelector parses a selector in the format 'key=regex[,key=regex...]'
+func parseSelector(s string) (taskSelector, error) {
+	selector := taskSelector{}
+	for _, term := range splitSelector(s) {
+		kv := strings.SplitN(term, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Bad selector format for %s, expected key=regex", term)
+		}
+		known := false
+		for _, k := range taskSelectorKeys {
+			known = known || k == kv[0]
+		}
+		if !known {
+			return nil, fmt.Errorf("Unknown selector key %s, expected one of: %s", kv[0], strings.Join(taskSelectorKeys, ", "))
+		}
+		re, err := regexp.Compile("^(?:" + kv[1] + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("Bad selector regex %s: %s", kv[1], err)
+		}
+		selector[kv[0]] = re
+	}
+	return selector, nil
+}
+
+func (s taskSelector) match(key string, values ...string) bool {
+	re, ok := s[key]
+	if !ok {
+		return true
+	}
+	for _, v := range values {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectTasks returns the running tasks matching the selector, along with the registered
+// agents by id (GET_STATE)
+func selectTasks(cli calls.Sender, selector taskSelector) ([]mesos.Task, map[string]master.Response_GetAgents_Agent, error) {
+	r, err := sendMasterCall(cli, calls.GetState())
+	if err != nil {
+		return nil, nil, err
+	}
+	state := r.GetGetState()
+	frameworks := map[string]string{}
+	for _, f := range state.GetGetFrameworks().GetFrameworks() {
+		frameworks[f.FrameworkInfo.GetID().GetValue()] = f.FrameworkInfo.GetName()
+	}
+	agents := map[string]master.Response_GetAgents_Agent{}
+	for _, a := range state.GetGetAgents().GetAgents() {
+		agents[a.GetAgentInfo().ID.Value] = a
+	}
+	tasks := []mesos.Task{}
+	for _, t := range state.GetGetTasks().GetTasks() {
+		if t.GetState() != mesos.TASK_RUNNING {
+			continue
+		}
+		a := agents[t.GetAgentID().Value]
+		if selector.match("framework", t.FrameworkID.Value, frameworks[t.FrameworkID.Value]) &&
+			selector.match("task", t.TaskID.Value) &&
+			selector.match("agent", t.GetAgentID().Value, a.GetAgentInfo().Hostname) {
+			tasks = append(tasks, t)
+		}
+	}
+	if len(tasks) == 0 {
+		return nil, nil, fmt.Errorf("Unable to find running task matching the selector")
+	}
+	return tasks, agents, nil
+}
+
+// findTasks returns the running tasks whose id starts with prefix (GET_TASKS)
+func findTasks(cli calls.Sender, prefix string) ([]mesos.Task, error) {
+	r, err := sendMasterCall(cli, calls.GetTasks())
+	if err != nil {
+		return nil, err
+	}
+	tasks := []mesos.Task{}
+	for _, t := range r.GetGetTasks().GetTasks() {
+		if t.GetState() == mesos.TASK_RUNNING && strings.HasPrefix(t.TaskID.Value, prefix) {
+			tasks = append(tasks, t)
+		}
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("Unable to find running task with id starting with %s", prefix)
+	}
+	return tasks, nil
+}
+
+// findTask is like findTasks but fails if prefix matches more than one task
+func findTask(cli calls.Sender, prefix string) (*mesos.Task, error) {
+	tasks, err := findTasks(cli, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) > 1 {
+		ids := []string{}
+		for _, t := range tasks {
+			ids = append(ids, t.TaskID.Value)
+		}
+		return nil, fmt.Errorf("Task %s is ambiguous, it matches: %s", prefix, strings.Join(ids, ", "))
+	}
+	return &tasks[0], nil
+}
+
+// taskAgentCli returns a client to the agent running the task
+func taskAgentCli(cli calls.Sender, task mesos.Task) (agentcalls.Sender, error) {
+	a, err := findAgent(cli, task.AgentID.Value)
+	if err != nil {
+		return nil, err
+	}
+	return registeredAgentCli(a.GetAgentInfo())
+}
+
+// registeredAgentCli returns a client to an agent registered on the master
+func registeredAgentCli(info mesos.AgentInfo) (agentcalls.Sender, error) {
+	agentCli, err := getCli(fmt.Sprintf("%s:%d", info.Hostname, info.GetPort()))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to reach agent %s: %s", info.Hostname, err)
+	}
+	return agentCli, nil
+}
+
+// taskExecutorID returns the id of the executor of the task, which is the task id for command tasks
+func taskExecutorID(task mesos.Task) string {
+	if task.ExecutorID != nil {
+		return task.ExecutorID.Value
+	}
+	return task.TaskID.Value
+}
+
+// taskContainerID returns the ID of the container running the task (GET_CONTAINERS).
+// Command tasks run in their executor container.
+// Tasks of task groups (pods) run in a container nested in the executor container.
+func taskContainerID(cli agentcalls.Sender, task mesos.Task) (*mesos.ContainerID, error) {
+	executorID := taskExecutorID(task)
+	r, err := sendAgentQuery(cli, agentcalls.GetContainers())
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range r.GetGetContainers().GetContainers() {
+		if c.GetFrameworkID().GetValue() != task.FrameworkID.Value || c.GetExecutorID().GetValue() != executorID {
+			continue
+		}
+		containerId := c.GetContainerID()
+		for i := len(task.Statuses) - 1; i >= 0; i-- {
+			id := task.Statuses[i].GetContainerStatus().GetContainerID()
+			if id != nil && id.Parent != nil && id.Parent.Value == containerId.Value {
+				return id, nil
+			}
+		}
+		return &containerId, nil
+	}
+	return nil, fmt.Errorf("Unable to find container of task %s", task.TaskID.Value)
+}
+
+// taskSandbox returns the sandbox directory of the task on the agent. The v1 API does not expose
+// the executor directory, so it is built from the agent work_dir flag (GET_FLAGS) like the agent does:
+// <work_dir>/slaves/<agent>/frameworks/<framework>/executors/<executor>/runs/<container>,
+// followed by tasks/<task> for tasks of task groups (pods), which run in nested containers.
+func taskSandbox(cli agentcalls.Sender, task mesos.Task) (string, error) {
+	containerId, err := taskContainerID(cli, task)
+	if err != nil {
+		return "", err
+	}
+	r, err := sendAgentQuery(cli, agentcalls.GetFlags())
+	if err != nil {
+		return "", err
+	}
+	workDir := ""
+	for _, f := range r.GetGetFlags().GetFlags() {
+		if f.GetName() == "work_dir" {
+			workDir = f.GetValue()
+		}
+	}
+	if workDir == "" {
+		return "", fmt.Errorf("Unable to find the work_dir flag of the agent")
+	}
+	executorContainerId := containerId
+	if containerId.Parent != nil {
+		executorContainerId = containerId.Parent
+	}
+	dir := path.Join(workDir, "slaves", task.AgentID.Value, "frameworks", task.FrameworkID.Value,
+		"executors", taskExecutorID(task), "runs", executorContainerId.Value)
+	if containerId.Parent != nil {
+		dir = path.Join(dir, "tasks", task.TaskID.Value)
+	}
+	return dir, nil
+}
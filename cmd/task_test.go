@@ -0,0 +1,80 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSelector(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"task=web", []string{"task=web"}},
+		{"framework=marathon,task=web.*", []string{"framework=marathon", "task=web.*"}},
+		{"task=web-[0-9]{1,3}", []string{"task=web-[0-9]{1,3}"}},
+		{"task=a|b,c,agent=host", []string{"task=a|b,c", "agent=host"}},
+		{"a,task=b", []string{"a", "task=b"}},
+	}
+	for _, test := range tests {
+		if got := splitSelector(test.in); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitSelector(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		in      string
+		key     string
+		match   []string
+		noMatch []string
+		wantErr bool
+	}{
+		{in: "task=web-[0-9]{1,3}", key: "task", match: []string{"web-1", "web-123"}, noMatch: []string{"web-1234", "web-"}},
+		{in: "task=a|b,c", key: "task", match: []string{"a", "b,c"}, noMatch: []string{"b", "c", "ab"}},
+		{in: "framework=marathon,agent=host.*", key: "agent", match: []string{"host1"}, noMatch: []string{"marathon"}},
+		{in: "task", wantErr: true},
+		{in: "bogus=a", wantErr: true},
+		{in: "task=web,bogus=a", wantErr: true},
+		{in: "task=(", wantErr: true},
+	}
+	for _, test := range tests {
+		selector, err := parseSelector(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseSelector(%q) succeeded, want an error", test.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSelector(%q) failed: %s", test.in, err)
+			continue
+		}
+		for _, v := range test.match {
+			if !selector.match(test.key, v) {
+				t.Errorf("parseSelector(%q) does not match %s %q", test.in, test.key, v)
+			}
+		}
+		for _, v := range test.noMatch {
+			if selector.match(test.key, v) {
+				t.Errorf("parseSelector(%q) matches %s %q", test.in, test.key, v)
+			}
+		}
+	}
+}
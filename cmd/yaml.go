@@ -0,0 +1,65 @@
+/*
+Copyright © 2020 Criteo
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// jsonValue converts a value parsed from YAML to a value which can be marshalled to JSON,
+// YAML maps having interface{} keys
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, value := range v {
+			m[fmt.Sprintf("%v", key)] = jsonValue(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonValue(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// readJSONOrYAML reads a YAML or JSON file into v, as JSON, so that the JSON field names
+// of protobuf messages are used
+func readJSONOrYAML(path string, v interface{}) error {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Error reading file %s: %s", path, err)
+	}
+	var parsed interface{}
+	// JSON being a subset of YAML, both are parsed as YAML
+	if err = yaml.Unmarshal(bytes, &parsed); err != nil {
+		return fmt.Errorf("Error parsing file %s: %s", path, err)
+	}
+	if bytes, err = json.Marshal(jsonValue(parsed)); err != nil {
+		return fmt.Errorf("Error parsing file %s: %s", path, err)
+	}
+	if err = json.Unmarshal(bytes, v); err != nil {
+		return fmt.Errorf("Error parsing file %s: %s", path, err)
+	}
+	return nil
+}